@@ -4,28 +4,30 @@ import (
 	"context"
 	"flag"
 	"log"
-	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/wittano/yomoid/config"
 	"github.com/wittano/yomoid/discord"
 	"github.com/wittano/yomoid/logger"
 )
 
 var (
-	update  = flag.Bool("update", false, "Update slash commands")
-	token   = flag.String("token", "", "Discord bot token")
-	appID   = flag.String("appID", "", "Discord bot application ID")
-	guildID = flag.String("guildID", "", "Guild ID")
+	update = flag.Bool("update", false, "Update slash commands")
+	loader = config.NewLoader(flag.CommandLine, "token", "appID", "guildID", "requestTimeout")
 )
 
 func main() {
 	flag.Parse()
 
-	if token == nil || *token == "" {
-		log.Fatal("missing required discord token")
+	cfg, err := loader.Load()
+	if err == nil {
+		err = cfg.ValidateCLI(*update)
+	}
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	bot, err := discordgo.New("Bot " + *token)
+	bot, err := discordgo.New("Bot " + cfg.Discord.Token)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -46,28 +48,20 @@ func main() {
 	<-readyCh
 
 	if update != nil && *update {
-		updateCommand(bot)
+		updateCommand(bot, cfg)
 	}
 }
 
-func updateCommand(s *discordgo.Session) {
-	if appID == nil || *appID == "" {
-		log.Fatal("yomoid: missing required appID value")
-	}
+func updateCommand(s *discordgo.Session, cfg config.Config) {
+	for _, cmd := range discord.CommandDefinitions() {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeouts.Request)
 
-	pollCmd := discord.NewPollCommandDefinition()
+		_, err := s.ApplicationCommandCreate(cfg.Discord.AppID, cfg.Discord.GuildID, cmd, discordgo.WithContext(ctx))
+		cancel()
+		if err != nil {
+			log.Fatal(err)
+		}
 
-	gID := ""
-	if guildID != nil && *guildID != "" {
-		gID = *guildID
+		log.Printf("yomoid: updated slash command '%s' for app '%s' on guild '%s'", cmd.Name, cfg.Discord.AppID, cfg.Discord.GuildID)
 	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	if _, err := s.ApplicationCommandCreate(*appID, gID, pollCmd, discordgo.WithContext(ctx)); err != nil {
-		log.Fatal(err)
-	}
-
-	log.Printf("yomoid: updated slash command for app '%s' on guild '%s'", *appID, gID)
 }
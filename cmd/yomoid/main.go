@@ -2,19 +2,25 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"io"
 	"log"
 	"log/slog"
-	"os"
-	"os/signal"
+	"net/http"
 	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/wittano/yomoid/config"
 	"github.com/wittano/yomoid/discord"
+	"github.com/wittano/yomoid/health"
+	"github.com/wittano/yomoid/lifecycle"
+	"github.com/wittano/yomoid/metrics"
 	"github.com/wittano/yomoid/ningegag"
 	"github.com/wittano/yomoid/poll"
+	"github.com/wittano/yomoid/settings"
+	"github.com/wittano/yomoid/tracing"
 )
 
 func closeAndLog(closer io.Closer) {
@@ -24,7 +30,11 @@ func closeAndLog(closer io.Closer) {
 }
 
 var (
-	level = flag.String("level", "", "Log level")
+	loader = config.NewLoader(flag.CommandLine,
+		"level", "httpAddr", "tracing", "tracingEndpoint", "databaseURL",
+		"databaseStartupTimeout", "commandTimeout", "deferredCommandTimeout", "httpClientTimeout", "shutdownTimeout", "jobTimeout", "settingsCacheTTL",
+		"pollRetention", "pollPurgeInterval", "pollRandomWindow", "pollScheduleInterval", "pollThreadArchiveInterval", "pollReminderInterval", "pollQuorumInterval", "pollCloseInterval",
+	)
 
 	bot *discordgo.Session
 )
@@ -32,58 +42,176 @@ var (
 func main() {
 	flag.Parse()
 
-	slog.SetLogLoggerLevel(parseLogLevel())
+	cfg, err := loader.Load()
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	token, ok := os.LookupEnv("DISCORD_TOKEN")
-	if !ok {
-		log.Fatal("Missing required environment variable: DISCORD_TOKEN")
+	slog.SetLogLoggerLevel(parseLogLevel(cfg.Log.Level))
+
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing.Exporter, cfg.Tracing.Endpoint)
+	if err != nil {
+		log.Fatalf("failed init tracing: %s", err)
 	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := shutdownTracing(ctx); err != nil {
+			log.Println(err)
+		}
+	}()
 
-	ctx, dbCancel := context.WithTimeout(context.Background(), time.Second)
+	ctx, dbCancel := context.WithTimeout(context.Background(), cfg.Timeouts.DatabaseStartup)
 	defer dbCancel()
-	db, err := poll.NewDatabase(ctx)
+	db, err := poll.NewDatabase(ctx, cfg.Database.URL)
 	if err != nil {
 		log.Fatalf("failed init database: %s", err)
 	}
+	metrics.RegisterPoolStats(db.Stat)
 
-	bot, err = discordgo.New("Bot " + token)
+	bot, err = discordgo.New("Bot " + cfg.Discord.Token)
 	if err != nil {
 		log.Fatalf("failed create discord session: %s", err)
 	}
-	defer closeAndLog(bot)
+	bot.Client.Transport = tracing.Transport{Base: metrics.Transport{Base: bot.Client.Transport}}
+
+	guildSettings := settings.NewService(db, cfg.Cache.SettingsTTL)
 
 	pollHandler := poll.MessageCreateHandler{
-		Db: db,
+		Db:       db,
+		Settings: guildSettings,
 	}
+	voteHandler := poll.VoteHandler{Db: db}
+	linkFixer := ningegag.MessageFixer{Settings: guildSettings}
+
+	discord.InitSlashCommandList(db, guildSettings, &pollHandler)
+	discord.SetTimeouts(cfg.Timeouts.Command, cfg.Timeouts.DeferredCommand, cfg.Timeouts.HTTPClient)
+	discord.SetRandomPollWindow(cfg.Polls.RandomWindow)
 
-	discord.InitSlashCommandList(db, &pollHandler)
+	lc := lifecycle.New()
+	discord.SetLifecycle(lc)
 
-	bot.AddHandler(ningegag.MessageFixer)
-	bot.AddHandler(pollHandler.Handler)
-	bot.AddHandler(discord.HandleSlashCommand)
+	bot.AddHandler(lifecycle.Handler(lc, linkFixer.Handler))
+	bot.AddHandler(lifecycle.Handler(lc, pollHandler.Handler))
+	bot.AddHandler(lifecycle.Handler(lc, voteHandler.VoteAdd))
+	bot.AddHandler(lifecycle.Handler(lc, voteHandler.VoteRemove))
+	bot.AddHandler(lifecycle.Handler(lc, discord.HandleInteraction))
 	bot.AddHandlerOnce(ready)
 
-	bot.Identify.Intents = discordgo.IntentMessageContent | discordgo.IntentGuildMessages
+	checker := &health.Checker{Session: bot, Db: db}
+	checker.AddHandlers(bot)
+
+	bot.Identify.Intents = discordgo.IntentMessageContent | discordgo.IntentGuildMessages | discordgo.IntentGuildMessagePolls
 
 	if err = bot.Open(); err != nil {
 		log.Fatal(err)
 	}
 
-	closeCh := make(chan os.Signal, 1)
-	signal.Notify(closeCh, os.Interrupt)
-	<-closeCh
+	server := serveHTTP(cfg.HTTP.Addr, checker)
+
+	purger := poll.Purger{Db: db, Retention: cfg.Polls.Retention, Interval: cfg.Polls.PurgeInterval}
+	if err = lc.Go(purger.Run); err != nil {
+		log.Fatal(err)
+	}
+
+	dailyPoller := discord.DailyPoller{
+		Db:       db,
+		Session:  bot,
+		Interval: cfg.Polls.ScheduleInterval,
+		Timeout:  cfg.Timeouts.Command,
+	}
+	if err = lc.Go(dailyPoller.Run); err != nil {
+		log.Fatal(err)
+	}
+
+	threadArchiver := discord.ThreadArchiver{
+		Db:       db,
+		Session:  bot,
+		Interval: cfg.Polls.ThreadArchiveInterval,
+		Timeout:  cfg.Timeouts.Job,
+	}
+	if err = lc.Go(threadArchiver.Run); err != nil {
+		log.Fatal(err)
+	}
+
+	reminderSender := discord.ReminderSender{
+		Db:       db,
+		Session:  bot,
+		Interval: cfg.Polls.ReminderInterval,
+		Timeout:  cfg.Timeouts.Job,
+	}
+	if err = lc.Go(reminderSender.Run); err != nil {
+		log.Fatal(err)
+	}
+
+	quorumChecker := discord.QuorumChecker{
+		Db:       db,
+		Session:  bot,
+		Interval: cfg.Polls.QuorumInterval,
+		Timeout:  cfg.Timeouts.Job,
+	}
+	if err = lc.Go(quorumChecker.Run); err != nil {
+		log.Fatal(err)
+	}
+
+	customPollCloser := discord.CustomPollCloser{
+		Db:       db,
+		Session:  bot,
+		Interval: cfg.Polls.CloseInterval,
+		Timeout:  cfg.Timeouts.Job,
+	}
+	if err = lc.Go(customPollCloser.Run); err != nil {
+		log.Fatal(err)
+	}
+
+	sig := lc.WaitForSignal()
+	slog.Info("received signal. Shutting down", "signal", sig.String())
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Timeouts.Shutdown)
+	defer cancel()
+
+	if err = lc.Shutdown(shutdownCtx); err != nil {
+		slog.Error("failed wait for in-flight work", "error", err)
+	}
+
+	if err = server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("failed shutdown HTTP server", "error", err)
+	}
+
+	db.Close()
+	closeAndLog(bot)
+
+	slog.Info("Bot stopped")
+}
+
+func serveHTTP(addr string, checker *health.Checker) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler())
+	mux.HandleFunc("GET /healthz", checker.Healthz)
+	mux.HandleFunc("GET /readyz", checker.Readyz)
+
+	server := &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		slog.Info("HTTP server listening", "address", addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("HTTP server stopped", "error", err)
+		}
+	}()
+
+	return server
 }
 
 func ready(_ *discordgo.Session, _ *discordgo.Ready) {
 	slog.Info("Bot is ready. Press CTRL+C to exit.")
 }
 
-func parseLogLevel() slog.Level {
-	if level == nil {
-		return slog.LevelInfo
-	}
-
-	switch strings.ToUpper(*level) {
+func parseLogLevel(level string) slog.Level {
+	switch strings.ToUpper(level) {
 	case "DEBUG":
 		return slog.LevelDebug
 	case "INFO":
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"sort"
+	"time"
+)
+
+// binding maps environment variable and command-line flag into config field
+type binding struct {
+	flag  string
+	env   string
+	usage string
+	set   func(c *Config, v string) error
+}
+
+var bindings = []binding{
+	{"token", "DISCORD_TOKEN", "Discord bot token", func(c *Config, v string) error {
+		c.Discord.Token = v
+		return nil
+	}},
+	{"appID", "YOMOID_DISCORD_APP_ID", "Discord bot application ID", func(c *Config, v string) error {
+		c.Discord.AppID = v
+		return nil
+	}},
+	{"guildID", "YOMOID_DISCORD_GUILD_ID", "Guild ID", func(c *Config, v string) error {
+		c.Discord.GuildID = v
+		return nil
+	}},
+	{"databaseURL", "DATABASE_URL", "PostgreSQL connection URL", func(c *Config, v string) error {
+		c.Database.URL = v
+		return nil
+	}},
+	{"level", "YOMOID_LOG_LEVEL", "Log level", func(c *Config, v string) error {
+		c.Log.Level = v
+		return nil
+	}},
+	{"httpAddr", "YOMOID_HTTP_ADDR", "Bind address of HTTP server with metrics and health checks", func(c *Config, v string) error {
+		c.HTTP.Addr = v
+		return nil
+	}},
+	{"tracing", "YOMOID_TRACING_EXPORTER", "Trace exporter: otlp, stdout or none", func(c *Config, v string) error {
+		c.Tracing.Exporter = v
+		return nil
+	}},
+	{"tracingEndpoint", "YOMOID_TRACING_ENDPOINT", "OTLP HTTP endpoint URL", func(c *Config, v string) error {
+		c.Tracing.Endpoint = v
+		return nil
+	}},
+	{"databaseStartupTimeout", "YOMOID_TIMEOUT_DATABASE_STARTUP", "Timeout of connecting to database on startup", durationSetter(func(c *Config) *time.Duration {
+		return &c.Timeouts.DatabaseStartup
+	})},
+	{"commandTimeout", "YOMOID_TIMEOUT_COMMAND", "Timeout of handling slash command", durationSetter(func(c *Config) *time.Duration {
+		return &c.Timeouts.Command
+	})},
+	{"deferredCommandTimeout", "YOMOID_TIMEOUT_DEFERRED_COMMAND", "Timeout of handling long-running slash command, e.g. posting poll to many channels", durationSetter(func(c *Config) *time.Duration {
+		return &c.Timeouts.DeferredCommand
+	})},
+	{"httpClientTimeout", "YOMOID_TIMEOUT_HTTP_CLIENT", "Timeout of HTTP client, e.g. downloading avatars", durationSetter(func(c *Config) *time.Duration {
+		return &c.Timeouts.HTTPClient
+	})},
+	{"requestTimeout", "YOMOID_TIMEOUT_REQUEST", "Timeout of Discord REST requests sent by CLI", durationSetter(func(c *Config) *time.Duration {
+		return &c.Timeouts.Request
+	})},
+	{"shutdownTimeout", "YOMOID_TIMEOUT_SHUTDOWN", "Maximum time for waiting on in-flight work during shutdown", durationSetter(func(c *Config) *time.Duration {
+		return &c.Timeouts.Shutdown
+	})},
+	{"jobTimeout", "YOMOID_TIMEOUT_JOB", "Timeout of single run of background job, e.g. checking quorum of closed poll", durationSetter(func(c *Config) *time.Duration {
+		return &c.Timeouts.Job
+	})},
+	{"settingsCacheTTL", "YOMOID_CACHE_SETTINGS_TTL", "Time of caching guild settings", durationSetter(func(c *Config) *time.Duration {
+		return &c.Cache.SettingsTTL
+	})},
+	{"pollRetention", "YOMOID_POLL_RETENTION", "Time, after which deleted polls are permanently removed", durationSetter(func(c *Config) *time.Duration {
+		return &c.Polls.Retention
+	})},
+	{"pollPurgeInterval", "YOMOID_POLL_PURGE_INTERVAL", "Interval of removing expired deleted polls", durationSetter(func(c *Config) *time.Duration {
+		return &c.Polls.PurgeInterval
+	})},
+	{"pollRandomWindow", "YOMOID_POLL_RANDOM_WINDOW", "Time, in which posted poll isn't picked again by /poll random", durationSetter(func(c *Config) *time.Duration {
+		return &c.Polls.RandomWindow
+	})},
+	{"pollScheduleInterval", "YOMOID_POLL_SCHEDULE_INTERVAL", "Interval of checking scheduled daily polls", durationSetter(func(c *Config) *time.Duration {
+		return &c.Polls.ScheduleInterval
+	})},
+	{"pollThreadArchiveInterval", "YOMOID_POLL_THREAD_ARCHIVE_INTERVAL", "Interval of archiving discussion threads of closed polls", durationSetter(func(c *Config) *time.Duration {
+		return &c.Polls.ThreadArchiveInterval
+	})},
+	{"pollReminderInterval", "YOMOID_POLL_REMINDER_INTERVAL", "Interval of sending reminders before polls close", durationSetter(func(c *Config) *time.Duration {
+		return &c.Polls.ReminderInterval
+	})},
+	{"pollQuorumInterval", "YOMOID_POLL_QUORUM_INTERVAL", "Interval of checking quorum of closed polls", durationSetter(func(c *Config) *time.Duration {
+		return &c.Polls.QuorumInterval
+	})},
+	{"pollCloseInterval", "YOMOID_POLL_CLOSE_INTERVAL", "Interval of closing polls posted with buttons engine", durationSetter(func(c *Config) *time.Duration {
+		return &c.Polls.CloseInterval
+	})},
+}
+
+func durationSetter(field func(c *Config) *time.Duration) func(c *Config, v string) error {
+	return func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+
+		*field(c) = d
+		return nil
+	}
+}
+
+func findBinding(flag string) (binding, bool) {
+	for _, b := range bindings {
+		if b.flag == flag {
+			return b, true
+		}
+	}
+
+	return binding{}, false
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,323 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"gopkg.in/yaml.v3"
+)
+
+type Discord struct {
+	Token   string `toml:"token" yaml:"token"`
+	AppID   string `toml:"app_id" yaml:"app_id"`
+	GuildID string `toml:"guild_id" yaml:"guild_id"`
+}
+
+type Database struct {
+	URL string `toml:"url" yaml:"url"`
+}
+
+type Log struct {
+	Level string `toml:"level" yaml:"level"`
+}
+
+type HTTP struct {
+	Addr string `toml:"addr" yaml:"addr"`
+}
+
+type Tracing struct {
+	Exporter string `toml:"exporter" yaml:"exporter"`
+	Endpoint string `toml:"endpoint" yaml:"endpoint"`
+}
+
+type Timeouts struct {
+	DatabaseStartup time.Duration `toml:"database_startup" yaml:"database_startup"`
+	Command         time.Duration `toml:"command" yaml:"command"`
+	// DeferredCommand limits commands, which answer later, e.g. posting poll to many channels
+	DeferredCommand time.Duration `toml:"deferred_command" yaml:"deferred_command"`
+	HTTPClient      time.Duration `toml:"http_client" yaml:"http_client"`
+	Request         time.Duration `toml:"request" yaml:"request"`
+	Shutdown        time.Duration `toml:"shutdown" yaml:"shutdown"`
+	// Job limits single run of background job, e.g. checking quorum of closed poll
+	Job time.Duration `toml:"job" yaml:"job"`
+}
+
+type Cache struct {
+	SettingsTTL time.Duration `toml:"settings_ttl" yaml:"settings_ttl"`
+}
+
+type Polls struct {
+	// Retention is time, after which deleted polls are permanently removed
+	Retention     time.Duration `toml:"retention" yaml:"retention"`
+	PurgeInterval time.Duration `toml:"purge_interval" yaml:"purge_interval"`
+	// RandomWindow is default time, in which posted poll isn't picked again by /poll random
+	RandomWindow     time.Duration `toml:"random_window" yaml:"random_window"`
+	ScheduleInterval time.Duration `toml:"schedule_interval" yaml:"schedule_interval"`
+	// ThreadArchiveInterval is interval of archiving discussion threads of closed polls
+	ThreadArchiveInterval time.Duration `toml:"thread_archive_interval" yaml:"thread_archive_interval"`
+	// ReminderInterval is interval of sending reminders before polls close
+	ReminderInterval time.Duration `toml:"reminder_interval" yaml:"reminder_interval"`
+	// QuorumInterval is interval of checking quorum of closed polls
+	QuorumInterval time.Duration `toml:"quorum_interval" yaml:"quorum_interval"`
+	// CloseInterval is interval of closing polls posted with buttons engine
+	CloseInterval time.Duration `toml:"close_interval" yaml:"close_interval"`
+}
+
+type Config struct {
+	Discord  Discord  `toml:"discord" yaml:"discord"`
+	Database Database `toml:"database" yaml:"database"`
+	Log      Log      `toml:"log" yaml:"log"`
+	HTTP     HTTP     `toml:"http" yaml:"http"`
+	Tracing  Tracing  `toml:"tracing" yaml:"tracing"`
+	Timeouts Timeouts `toml:"timeouts" yaml:"timeouts"`
+	Cache    Cache    `toml:"cache" yaml:"cache"`
+	Polls    Polls    `toml:"polls" yaml:"polls"`
+}
+
+func Default() Config {
+	return Config{
+		Log:     Log{Level: "INFO"},
+		HTTP:    HTTP{Addr: ":8080"},
+		Tracing: Tracing{Exporter: "none"},
+		Timeouts: Timeouts{
+			DatabaseStartup: time.Second,
+			Command:         2 * time.Second,
+			DeferredCommand: 30 * time.Second,
+			HTTPClient:      5 * time.Second,
+			Request:         10 * time.Second,
+			Shutdown:        8 * time.Second,
+			Job:             30 * time.Second,
+		},
+		Cache: Cache{SettingsTTL: 5 * time.Minute},
+		Polls: Polls{
+			Retention:             30 * 24 * time.Hour,
+			PurgeInterval:         time.Hour,
+			RandomWindow:          14 * 24 * time.Hour,
+			ScheduleInterval:      time.Minute,
+			ThreadArchiveInterval: time.Minute,
+			ReminderInterval:      time.Minute,
+			QuorumInterval:        time.Minute,
+			CloseInterval:         time.Minute,
+		},
+	}
+}
+
+const PathEnv = "YOMOID_CONFIG"
+
+// LoadFile overlays c with values from TOML or YAML file. Format is detected by file extension
+func (c *Config) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config: failed read %s: %w", path, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		meta, err := toml.Decode(string(data), c)
+		if err != nil {
+			return fmt.Errorf("config: invalid TOML file %s: %w", path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("config: unknown keys in %s: %v", path, undecoded)
+		}
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err = dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("config: invalid YAML file %s: %w", path, err)
+		}
+	default:
+		return fmt.Errorf("config: unsupported config file format %q. Use .toml, .yaml or .yml", filepath.Ext(path))
+	}
+
+	return nil
+}
+
+// LoadEnv overlays c with values from environment variables
+func (c *Config) LoadEnv() error {
+	var errs []error
+	for _, b := range bindings {
+		if b.env == "" {
+			continue
+		}
+
+		if v, ok := os.LookupEnv(b.env); ok && v != "" {
+			if err := b.set(c, v); err != nil {
+				errs = append(errs, fmt.Errorf("config: invalid %s environment variable: %w", b.env, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate checks all values and fields required by the bot
+func (c Config) Validate() error {
+	errs := c.validate()
+	if c.Database.URL == "" {
+		errs = append(errs, errors.New("database.url is required (DATABASE_URL)"))
+	}
+
+	return joinValidationErrors(errs)
+}
+
+// ValidateCLI checks all values and fields required by CLI. Application ID is required only for updating slash commands
+func (c Config) ValidateCLI(update bool) error {
+	errs := c.validate()
+	if update && c.Discord.AppID == "" {
+		errs = append(errs, errors.New("discord.app_id is required (-appID)"))
+	}
+
+	return joinValidationErrors(errs)
+}
+
+func (c Config) validate() (errs []error) {
+	if strings.TrimSpace(c.Discord.Token) == "" {
+		errs = append(errs, errors.New("discord.token is required (DISCORD_TOKEN)"))
+	}
+
+	if c.Database.URL != "" {
+		// Both URL and libpq key=value connection strings are accepted by pgx
+		if _, err := pgxpool.ParseConfig(c.Database.URL); err != nil {
+			errs = append(errs, fmt.Errorf("database.url is invalid: %w", err))
+		}
+	}
+
+	switch strings.ToUpper(c.Log.Level) {
+	case "DEBUG", "INFO", "WARN", "ERROR":
+	default:
+		errs = append(errs, fmt.Errorf("log.level %q is invalid. Use DEBUG, INFO, WARN or ERROR", c.Log.Level))
+	}
+
+	if _, _, err := net.SplitHostPort(c.HTTP.Addr); err != nil {
+		errs = append(errs, fmt.Errorf("http.addr %q is invalid: %w", c.HTTP.Addr, err))
+	}
+
+	switch c.Tracing.Exporter {
+	case "none", "stdout":
+	case "otlp":
+		if c.Tracing.Endpoint != "" {
+			if _, err := url.ParseRequestURI(c.Tracing.Endpoint); err != nil {
+				errs = append(errs, fmt.Errorf("tracing.endpoint %q is invalid: %w", c.Tracing.Endpoint, err))
+			}
+		}
+	default:
+		errs = append(errs, fmt.Errorf("tracing.exporter %q is invalid. Use otlp, stdout or none", c.Tracing.Exporter))
+	}
+
+	timeouts := map[string]time.Duration{
+		"timeouts.database_startup":     c.Timeouts.DatabaseStartup,
+		"timeouts.command":              c.Timeouts.Command,
+		"timeouts.deferred_command":     c.Timeouts.DeferredCommand,
+		"timeouts.http_client":          c.Timeouts.HTTPClient,
+		"timeouts.request":              c.Timeouts.Request,
+		"timeouts.shutdown":             c.Timeouts.Shutdown,
+		"timeouts.job":                  c.Timeouts.Job,
+		"cache.settings_ttl":            c.Cache.SettingsTTL,
+		"polls.retention":               c.Polls.Retention,
+		"polls.purge_interval":          c.Polls.PurgeInterval,
+		"polls.random_window":           c.Polls.RandomWindow,
+		"polls.schedule_interval":       c.Polls.ScheduleInterval,
+		"polls.thread_archive_interval": c.Polls.ThreadArchiveInterval,
+		"polls.reminder_interval":       c.Polls.ReminderInterval,
+		"polls.quorum_interval":         c.Polls.QuorumInterval,
+		"polls.close_interval":          c.Polls.CloseInterval,
+	}
+	for _, name := range sortedKeys(timeouts) {
+		if timeouts[name] <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be greater than 0", name))
+		}
+	}
+
+	// Discord requires an initial interaction response in 3 seconds
+	if c.Timeouts.Command >= 3*time.Second {
+		errs = append(errs, errors.New("timeouts.command must be less than 3s"))
+	}
+
+	return
+}
+
+func joinValidationErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	msg := make([]string, len(errs))
+	for i, err := range errs {
+		msg[i] = " - " + err.Error()
+	}
+
+	return fmt.Errorf("config: invalid configuration:\n%s", strings.Join(msg, "\n"))
+}
+
+// Loader loads configuration in order: defaults, config file, environment variables and command-line flags
+type Loader struct {
+	fs     *flag.FlagSet
+	path   *string
+	values map[string]*string
+}
+
+// NewLoader registers -config flag and selected configuration flags in fs
+func NewLoader(fs *flag.FlagSet, flags ...string) *Loader {
+	l := &Loader{
+		fs:     fs,
+		path:   fs.String("config", "", "Path to TOML or YAML config file. Can be set by "+PathEnv),
+		values: make(map[string]*string, len(flags)),
+	}
+
+	for _, name := range flags {
+		b, ok := findBinding(name)
+		if !ok {
+			panic("config: unknown flag " + name)
+		}
+
+		l.values[name] = fs.String(name, "", b.usage)
+	}
+
+	return l
+}
+
+// Load must be called after flags are parsed
+func (l *Loader) Load() (Config, error) {
+	cfg := Default()
+
+	path := *l.path
+	if path == "" {
+		path = os.Getenv(PathEnv)
+	}
+	if path != "" {
+		if err := cfg.LoadFile(path); err != nil {
+			return cfg, err
+		}
+	}
+
+	if err := cfg.LoadEnv(); err != nil {
+		return cfg, err
+	}
+
+	var errs []error
+	l.fs.Visit(func(f *flag.Flag) {
+		v, ok := l.values[f.Name]
+		if !ok {
+			return
+		}
+
+		b, _ := findBinding(f.Name)
+		if err := b.set(&cfg, *v); err != nil {
+			errs = append(errs, fmt.Errorf("config: invalid -%s flag: %w", f.Name, err))
+		}
+	})
+
+	return cfg, errors.Join(errs...)
+}
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+create table guild_settings
+(
+    guild_id         varchar primary key check ( trim(guild_id) <> '' ),
+    link_fixing      bool        not null default true,
+    auto_capture     bool        not null default true,
+    capture_channels varchar[]   not null default '{}',
+    admin_role_id    varchar,
+    default_duration int2        not null check ( default_duration in (1, 4, 8, 24, 72, 168, 336) ) default 24,
+    updated_at       timestamptz not null default now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists guild_settings;
+-- +goose StatementEnd
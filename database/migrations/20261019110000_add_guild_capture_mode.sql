@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table guild_settings
+    add column capture_mode  varchar   not null check ( capture_mode in ('off', 'allowlist', 'everywhere') ) default 'allowlist',
+    add column capture_roles varchar[] not null default '{}',
+    add column capture_quiet bool      not null default false;
+
+update guild_settings
+set capture_mode = case
+                       when not auto_capture then 'off'
+                       when cardinality(capture_channels) > 0 then 'allowlist'
+                       else 'everywhere' end;
+
+alter table guild_settings
+    drop column auto_capture;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table guild_settings
+    add column auto_capture bool not null default true;
+
+update guild_settings
+set auto_capture = capture_mode <> 'off';
+
+alter table guild_settings
+    drop column capture_mode,
+    drop column capture_roles,
+    drop column capture_quiet;
+-- +goose StatementEnd
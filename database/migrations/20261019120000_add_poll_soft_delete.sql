@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table poll
+    add column deleted_at timestamptz;
+
+drop index if exists poll_idx;
+create unique index poll_idx on poll (question, guild_id) where deleted_at is null;
+create index poll_deleted_at_idx on poll (deleted_at) where deleted_at is not null;
+
+-- poll_id isn't foreign key, because history has to survive purging deleted polls
+create table poll_audit
+(
+    id         bigint primary key generated always as identity,
+    poll_id    bigint      not null,
+    guild_id   varchar     not null check ( trim(guild_id) <> '' ),
+    actor_id   varchar     not null check ( trim(actor_id) <> '' ),
+    action     varchar(16) not null check ( action in ('created', 'edited', 'posted', 'deleted', 'restored') ),
+    created_at timestamptz not null default now()
+);
+
+create index poll_audit_poll_idx on poll_audit (poll_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists poll_audit;
+
+delete
+from poll_option
+where poll_id in (select id from poll where deleted_at is not null);
+
+delete
+from poll
+where deleted_at is not null;
+
+drop index if exists poll_deleted_at_idx;
+drop index if exists poll_idx;
+create unique index poll_idx on poll (question, guild_id);
+
+alter table poll
+    drop column deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,51 @@
+-- +goose Up
+-- +goose StatementBegin
+create table poll_revision
+(
+    id         bigint primary key generated always as identity,
+    poll_id    bigint       not null references poll,
+    revision   int4         not null check ( revision > 0 ),
+    question   varchar(300) not null check ( trim(question) <> '' ),
+    is_multi   bool         not null,
+    duration   int2         not null,
+    answers    varchar[]    not null,
+    emojis     varchar[]    not null check ( cardinality(emojis) = cardinality(answers) ),
+    author_id  varchar      not null check ( trim(author_id) <> '' ),
+    created_at timestamptz  not null default now(),
+    unique (poll_id, revision)
+);
+
+insert into poll_revision(poll_id, revision, question, is_multi, duration, answers, emojis, author_id, created_at)
+select p.id,
+       1,
+       p.question,
+       p.is_multi,
+       p.duration,
+       coalesce(array_agg(po.answer order by po.id) filter ( where po.id is not null ), '{}'),
+       coalesce(array_agg(coalesce(po.emoji, '') order by po.id) filter ( where po.id is not null ), '{}'),
+       p.author_id,
+       p.created_at
+from poll p
+         left join poll_option po on p.id = po.poll_id
+group by p.id;
+
+create table poll_post
+(
+    id          bigint primary key generated always as identity,
+    poll_id     bigint      not null references poll,
+    revision_id bigint      not null references poll_revision,
+    guild_id    varchar     not null check ( trim(guild_id) <> '' ),
+    channel_id  varchar     not null check ( trim(channel_id) <> '' ),
+    message_id  varchar     not null check ( trim(message_id) <> '' ),
+    posted_by   varchar     not null check ( trim(posted_by) <> '' ),
+    posted_at   timestamptz not null default now()
+);
+
+create index poll_post_poll_idx on poll_post (poll_id, posted_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists poll_post;
+drop table if exists poll_revision;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+create table poll_tag
+(
+    id       bigint primary key generated always as identity,
+    guild_id varchar     not null check ( trim(guild_id) <> '' ),
+    name     varchar(32) not null check ( trim(name) <> '' ),
+    unique (guild_id, name)
+);
+
+create table poll_tag_link
+(
+    poll_id bigint not null references poll on delete cascade,
+    tag_id  bigint not null references poll_tag on delete cascade,
+    primary key (poll_id, tag_id)
+);
+
+create index poll_tag_link_tag_idx on poll_tag_link (tag_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists poll_tag_link;
+drop table if exists poll_tag;
+-- +goose StatementEnd
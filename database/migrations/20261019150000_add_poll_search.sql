@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+create extension if not exists pg_trgm;
+create extension if not exists unaccent;
+
+-- unaccent is only stable, so it can't be used in indexes and generated columns directly
+create or replace function f_unaccent(text) returns text
+    language sql
+    immutable
+    parallel safe
+    strict
+as
+$$
+select public.unaccent('public.unaccent', $1)
+$$;
+
+-- search_text contains question and all answers. It's updated, when answers are changed
+alter table poll
+    add column search_text   text     not null default '',
+    add column search_vector tsvector not null generated always as ( to_tsvector('simple', f_unaccent(search_text)) ) stored;
+
+update poll p
+set search_text = concat_ws(' ', p.question, (select string_agg(po.answer, ' ' order by po.id)
+                                              from poll_option po
+                                              where po.poll_id = p.id));
+
+create index poll_search_vector_idx on poll using gin (search_vector);
+create index poll_search_text_trgm_idx on poll using gin (f_unaccent(lower(search_text)) gin_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index if exists poll_search_text_trgm_idx;
+drop index if exists poll_search_vector_idx;
+
+alter table poll
+    drop column search_vector,
+    drop column search_text;
+
+drop function if exists f_unaccent(text);
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table poll_tag_link
+    add column weight int2 not null check ( weight between 1 and 100 ) default 1;
+
+create table poll_schedule
+(
+    id          bigint primary key generated always as identity,
+    guild_id    varchar     not null check ( trim(guild_id) <> '' ),
+    channel_id  varchar     not null check ( trim(channel_id) <> '' ),
+    tag         varchar(32) not null check ( trim(tag) <> '' ),
+    hour        int2        not null check ( hour between 0 and 23 ),
+    -- 0 means default window from bot configuration
+    avoid_days  int2        not null check ( avoid_days >= 0 ) default 0,
+    next_run_at timestamptz not null,
+    created_by  varchar     not null check ( trim(created_by) <> '' ),
+    unique (guild_id, channel_id)
+);
+
+create index poll_schedule_next_run_idx on poll_schedule (next_run_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists poll_schedule;
+
+alter table poll_tag_link
+    drop column weight;
+-- +goose StatementEnd
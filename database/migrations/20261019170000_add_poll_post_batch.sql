@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- batch_id groups copies of poll posted by single command to many channels
+alter table poll_post
+    add column batch_id varchar;
+
+create index poll_post_batch_idx on poll_post (batch_id) where batch_id is not null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index if exists poll_post_batch_idx;
+
+alter table poll_post
+    drop column batch_id;
+-- +goose StatementEnd
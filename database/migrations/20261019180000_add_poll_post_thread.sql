@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table guild_settings
+    add column poll_threads bool not null default false;
+
+alter table poll_post
+    add column thread_id          varchar,
+    add column closes_at          timestamptz,
+    add column thread_archived_at timestamptz;
+
+create index poll_post_thread_idx on poll_post (closes_at) where thread_id is not null and thread_archived_at is null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index if exists poll_post_thread_idx;
+
+alter table poll_post
+    drop column thread_id,
+    drop column closes_at,
+    drop column thread_archived_at;
+
+alter table guild_settings
+    drop column poll_threads;
+-- +goose StatementEnd
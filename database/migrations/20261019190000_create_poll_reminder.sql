@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+create table poll_reminder
+(
+    id          bigint primary key generated always as identity,
+    post_id     bigint      not null references poll_post (id) on delete cascade,
+    guild_id    varchar     not null check ( trim(guild_id) <> '' ),
+    channel_id  varchar     not null check ( trim(channel_id) <> '' ),
+    message_id  varchar     not null check ( trim(message_id) <> '' ),
+    role_id     varchar     not null check ( trim(role_id) <> '' ),
+    remind_at   timestamptz not null,
+    -- reminder is skipped, when poll has at least min_turnout votes. 0 means reminder is always sent
+    min_turnout int4        not null check ( min_turnout >= 0 ) default 0,
+    created_by  varchar     not null check ( trim(created_by) <> '' ),
+    created_at  timestamptz not null default now(),
+    done_at     timestamptz,
+    skipped     bool        not null default false
+);
+
+create index poll_reminder_due_idx on poll_reminder (remind_at) where done_at is null;
+create index poll_reminder_guild_idx on poll_reminder (guild_id) where done_at is null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists poll_reminder;
+-- +goose StatementEnd
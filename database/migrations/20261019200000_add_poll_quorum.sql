@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+-- quorum_required of quorum set as percent of role members is resolved, when poll closes
+alter table poll_post
+    add column quorum_required   int4 check ( quorum_required > 0 ),
+    add column quorum_percent    int2 check ( quorum_percent between 1 and 100 ),
+    add column quorum_role_id    varchar,
+    add column quorum_repost     bool not null default false,
+    add column quorum_met        bool,
+    add column quorum_checked_at timestamptz;
+
+create index poll_post_quorum_idx on poll_post (closes_at) where quorum_required is not null and quorum_checked_at is null;
+create unique index poll_post_message_idx on poll_post (channel_id, message_id);
+
+-- poll_vote tracks voters of posts with quorum
+create table poll_vote
+(
+    post_id   bigint      not null references poll_post (id) on delete cascade,
+    user_id   varchar     not null check ( trim(user_id) <> '' ),
+    answer_id int4        not null,
+    voted_at  timestamptz not null default now(),
+    primary key (post_id, user_id, answer_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists poll_vote;
+
+drop index if exists poll_post_message_idx;
+drop index if exists poll_post_quorum_idx;
+
+alter table poll_post
+    drop column quorum_required,
+    drop column quorum_percent,
+    drop column quorum_role_id,
+    drop column quorum_repost,
+    drop column quorum_met,
+    drop column quorum_checked_at;
+-- +goose StatementEnd
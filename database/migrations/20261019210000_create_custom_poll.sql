@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table poll_post
+    add column engine varchar not null check ( engine in ('native', 'buttons') ) default 'native';
+
+-- custom_poll is rendered snapshot of template posted with buttons engine. Votes refer to index of answer
+create table custom_poll
+(
+    post_id       bigint primary key references poll_post (id) on delete cascade,
+    question      varchar     not null check ( trim(question) <> '' ),
+    answers       varchar[]   not null check ( cardinality(answers) between 1 and 25 ),
+    emojis        varchar[]   not null,
+    is_multi      bool        not null default false,
+    anonymous     bool        not null default false,
+    voter_role_id varchar,
+    closes_at     timestamptz not null,
+    closed_at     timestamptz
+);
+
+create index custom_poll_open_idx on custom_poll (closes_at) where closed_at is null;
+
+create table custom_poll_vote
+(
+    post_id  bigint      not null references custom_poll (post_id) on delete cascade,
+    user_id  varchar     not null check ( trim(user_id) <> '' ),
+    answer   int2        not null check ( answer >= 0 ),
+    voted_at timestamptz not null default now(),
+    primary key (post_id, user_id, answer)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists custom_poll_vote;
+drop table if exists custom_poll;
+
+alter table poll_post
+    drop column engine;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table poll
+    add column voting_method varchar not null check ( voting_method in ('plurality', 'approval', 'ranked') ) default 'plurality';
+
+alter table custom_poll
+    add column voting_method varchar not null check ( voting_method in ('plurality', 'approval', 'ranked') ) default 'plurality';
+
+-- rank is position of answer on ranked ballot. Votes of other voting methods have rank 0
+alter table custom_poll_vote
+    add column rank int2 not null check ( rank >= 0 ) default 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table custom_poll_vote
+    drop column rank;
+
+alter table custom_poll
+    drop column voting_method;
+
+alter table poll
+    drop column voting_method;
+-- +goose StatementEnd
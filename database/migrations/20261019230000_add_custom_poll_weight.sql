@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- custom_poll_weight gives votes of role members higher weight. Member with many weighted roles gets the highest one
+create table custom_poll_weight
+(
+    post_id bigint  not null references custom_poll (post_id) on delete cascade,
+    role_id varchar not null check ( trim(role_id) <> '' ),
+    weight  int2    not null check ( weight between 2 and 10 ),
+    primary key (post_id, role_id)
+);
+
+-- weight is computed from voter's roles at time of voting
+alter table custom_poll_vote
+    add column weight int2 not null check ( weight between 1 and 10 ) default 1;
+
+alter table poll_audit
+    add column details varchar;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table poll_audit
+    drop column details;
+
+alter table custom_poll_vote
+    drop column weight;
+
+drop table if exists custom_poll_weight;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table custom_poll
+    add column allow_other bool not null default false;
+
+-- custom_poll_reply is free-text answer typed by voter in "Other…" modal
+create table custom_poll_reply
+(
+    post_id    bigint      not null references custom_poll (post_id) on delete cascade,
+    user_id    varchar     not null check ( trim(user_id) <> '' ),
+    reply      varchar(55) not null check ( trim(reply) <> '' ),
+    replied_at timestamptz not null default now(),
+    primary key (post_id, user_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists custom_poll_reply;
+
+alter table custom_poll
+    drop column allow_other;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- emoji keeps name of emoji. Custom emoji of guild needs ID too
+alter table poll_option
+    add column emoji_id       varchar check ( trim(emoji_id) <> '' ),
+    add column emoji_animated bool not null default false;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table poll_option
+    drop column emoji_id,
+    drop column emoji_animated;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- quorum_required of quorum set as percent of role members is resolved, when poll closes
+drop index if exists poll_post_quorum_idx;
+create index poll_post_quorum_idx on poll_post (closes_at)
+    where (quorum_required is not null or quorum_percent is not null) and quorum_checked_at is null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index if exists poll_post_quorum_idx;
+create index poll_post_quorum_idx on poll_post (closes_at) where quorum_required is not null and quorum_checked_at is null;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- quorum_attempts counts failed quorum checks. Quorum is left unresolved after too many failures
+alter table poll_post
+    add column quorum_attempts int2 not null default 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table poll_post
+    drop column quorum_attempts;
+-- +goose StatementEnd
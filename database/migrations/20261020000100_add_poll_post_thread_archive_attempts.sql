@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- thread_archive_attempts counts failed archiving of discussion thread. Thread is given up after too many failures
+alter table poll_post
+    add column thread_archive_attempts int2 not null default 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table poll_post
+    drop column thread_archive_attempts;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- attempts counts failed sending of reminder. Reminder is skipped after too many failures
+alter table poll_reminder
+    add column attempts int2 not null default 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table poll_reminder
+    drop column attempts;
+-- +goose StatementEnd
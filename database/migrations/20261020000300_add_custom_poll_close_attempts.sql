@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- close_attempts counts failed closing of poll. Poll is closed without final tally after too many failures
+alter table custom_poll
+    add column close_attempts int2 not null default 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table custom_poll
+    drop column close_attempts;
+-- +goose StatementEnd
@@ -0,0 +1,107 @@
+-- name: CreateCustomPoll :exec
+insert into custom_poll(post_id, question, answers, emojis, is_multi, anonymous, voter_role_id, closes_at, voting_method,
+                        allow_other)
+values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+
+-- name: FindCustomPollByMessage :one
+select cp.*, pp.poll_id, pp.guild_id, pp.channel_id, pp.message_id, pp.posted_by
+from custom_poll cp
+         join poll_post pp on pp.id = cp.post_id
+where pp.channel_id = $1
+  and pp.message_id = $2;
+
+-- name: FindCustomPollsToClose :many
+select cp.*, pp.poll_id, pp.guild_id, pp.channel_id, pp.message_id, pp.posted_by
+from custom_poll cp
+         join poll_post pp on pp.id = cp.post_id
+where cp.closed_at is null
+  and cp.closes_at <= $1
+order by cp.closes_at
+limit 100;
+
+-- name: CloseCustomPoll :exec
+update custom_poll
+set closed_at = now()
+where post_id = $1;
+
+-- name: FailCustomPollClose :one
+update custom_poll
+set close_attempts = close_attempts + 1
+where post_id = $1
+returning close_attempts;
+
+-- name: LockCustomPoll :exec
+select post_id
+from custom_poll
+where post_id = $1
+    for update;
+
+-- name: FindCustomPollUserVotes :many
+select answer
+from custom_poll_vote
+where post_id = $1
+  and user_id = $2
+order by rank, answer;
+
+-- name: DeleteCustomPollUserVotes :exec
+delete
+from custom_poll_vote
+where post_id = $1
+  and user_id = $2;
+
+-- name: CreateCustomPollVote :exec
+insert into custom_poll_vote(post_id, user_id, answer, rank, weight)
+values ($1, $2, $3, $4, $5);
+
+-- name: FindCustomPollVotes :many
+select user_id, answer, rank, weight
+from custom_poll_vote
+where post_id = $1
+order by voted_at, user_id, rank;
+
+-- name: FindCustomPollBallots :many
+select post_id, user_id, answer, weight
+from custom_poll_vote
+where post_id = any (sqlc.arg(post_ids) :: bigint[])
+order by post_id, user_id, rank;
+
+-- name: CreateCustomPollWeight :exec
+insert into custom_poll_weight(post_id, role_id, weight)
+values ($1, $2, $3);
+
+-- name: FindCustomPollWeights :many
+select role_id, weight
+from custom_poll_weight
+where post_id = $1
+order by weight desc, role_id;
+
+-- name: UpsertCustomPollReply :exec
+insert into custom_poll_reply(post_id, user_id, reply)
+values ($1, $2, $3)
+on conflict (post_id, user_id) do update set reply      = excluded.reply,
+                                             replied_at = now();
+
+-- name: DeleteCustomPollReply :exec
+delete
+from custom_poll_reply
+where post_id = $1
+  and user_id = $2;
+
+-- name: FindCustomPollUserReply :one
+select reply
+from custom_poll_reply
+where post_id = $1
+  and user_id = $2;
+
+-- name: FindCustomPollReplies :many
+select min(reply) :: text as reply, count(*) as replies
+from custom_poll_reply
+where post_id = $1
+group by lower(trim(reply))
+order by replies desc, reply
+offset $2 limit $3;
+
+-- name: CountCustomPollReplies :one
+select count(distinct lower(trim(reply)))
+from custom_poll_reply
+where post_id = $1;
@@ -0,0 +1,26 @@
+-- name: FindGuildSettings :one
+select guild_id,
+       link_fixing,
+       capture_mode,
+       capture_channels,
+       capture_roles,
+       capture_quiet,
+       admin_role_id,
+       default_duration,
+       poll_threads
+from guild_settings
+where guild_id = $1;
+
+-- name: SaveGuildSettings :exec
+insert into guild_settings(guild_id, link_fixing, capture_mode, capture_channels, capture_roles, capture_quiet,
+                           admin_role_id, default_duration, poll_threads)
+values ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+on conflict (guild_id) do update set link_fixing      = excluded.link_fixing,
+                                     capture_mode     = excluded.capture_mode,
+                                     capture_channels = excluded.capture_channels,
+                                     capture_roles    = excluded.capture_roles,
+                                     capture_quiet    = excluded.capture_quiet,
+                                     admin_role_id    = excluded.admin_role_id,
+                                     default_duration = excluded.default_duration,
+                                     poll_threads     = excluded.poll_threads,
+                                     updated_at       = now();
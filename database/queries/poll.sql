@@ -4,8 +4,8 @@ values ($1, $2, $3, $4, $5)
 returning id;
 
 -- name: CreatePollOption :exec
-insert into poll_option(answer, emoji, poll_id)
-VALUES ($1, $2, $3);
+insert into poll_option(answer, emoji, emoji_id, emoji_animated, poll_id)
+VALUES ($1, $2, $3, $4, $5);
 
 -- name: FindPollByID :one
 select p.id,
@@ -14,12 +14,27 @@ select p.id,
        p.author_id,
        p.is_multi,
        p.duration,
+       p.voting_method,
        p.created_at,
-       array_agg(concat(po.emoji, '  ', po.answer)) :: text[] as options
+       (select r.id
+        from poll_revision r
+        where r.poll_id = p.id
+        order by r.revision desc
+        limit 1)                                                                                                     as revision_id,
+       coalesce(array_agg(po.answer order by po.id) filter ( where po.id is not null ), '{}') :: text[]                   as answers,
+       coalesce(array_agg(coalesce(po.emoji, '') order by po.id) filter ( where po.id is not null ), '{}') :: text[]    as emoji_names,
+       coalesce(array_agg(coalesce(po.emoji_id, '') order by po.id) filter ( where po.id is not null ), '{}') :: text[] as emoji_ids,
+       coalesce(array_agg(po.emoji_animated order by po.id) filter ( where po.id is not null ), '{}') :: bool[]         as emoji_animated,
+       array(select t.name
+             from poll_tag t
+                      join poll_tag_link pt on t.id = pt.tag_id
+             where pt.poll_id = p.id
+             order by t.name) :: text[]                    as tags
 from poll p
          left join poll_option po on p.id = po.poll_id
 where p.id = $1
   and p.guild_id = $2
+  and p.deleted_at is null
 group by p.id;
 
 -- name: FindPollByQuestion :many
@@ -29,12 +44,34 @@ select p.id,
        p.author_id,
        p.is_multi,
        p.duration,
+       p.voting_method,
        p.created_at,
-       array_agg(concat(po.emoji, '  ', po.answer)) :: text[] as options
+       (select r.id
+        from poll_revision r
+        where r.poll_id = p.id
+        order by r.revision desc
+        limit 1)                                                                                                     as revision_id,
+       coalesce(array_agg(po.answer order by po.id) filter ( where po.id is not null ), '{}') :: text[]                   as answers,
+       coalesce(array_agg(coalesce(po.emoji, '') order by po.id) filter ( where po.id is not null ), '{}') :: text[]    as emoji_names,
+       coalesce(array_agg(coalesce(po.emoji_id, '') order by po.id) filter ( where po.id is not null ), '{}') :: text[] as emoji_ids,
+       coalesce(array_agg(po.emoji_animated order by po.id) filter ( where po.id is not null ), '{}') :: bool[]         as emoji_animated,
+       array(select t.name
+             from poll_tag t
+                      join poll_tag_link pt on t.id = pt.tag_id
+             where pt.poll_id = p.id
+             order by t.name) :: text[]                    as tags,
+       -- snippet is matched only by SearchPoll. Both queries return the same columns, so rows are mapped alike
+       '' :: text                                      as snippet
 from poll p
          left join poll_option po on p.id = po.poll_id
 where p.question ilike concat('%', $1 :: text, '%')
   and p.guild_id = $2
+  and p.deleted_at is null
+  and ($4 :: text = '' or exists (select 1
+                                  from poll_tag_link pt
+                                           join poll_tag t on t.id = pt.tag_id
+                                  where pt.poll_id = p.id
+                                    and t.name = $4))
 group by p.id
 offset $3 limit 10;
 
@@ -45,19 +82,63 @@ select p.id,
        p.author_id,
        p.is_multi,
        p.duration,
+       p.voting_method,
        p.created_at,
-       array_agg(concat(po.emoji, '  ', po.answer)) :: text[] as options
+       (select r.id
+        from poll_revision r
+        where r.poll_id = p.id
+        order by r.revision desc
+        limit 1)                                                                                                     as revision_id,
+       coalesce(array_agg(po.answer order by po.id) filter ( where po.id is not null ), '{}') :: text[]                   as answers,
+       coalesce(array_agg(coalesce(po.emoji, '') order by po.id) filter ( where po.id is not null ), '{}') :: text[]    as emoji_names,
+       coalesce(array_agg(coalesce(po.emoji_id, '') order by po.id) filter ( where po.id is not null ), '{}') :: text[] as emoji_ids,
+       coalesce(array_agg(po.emoji_animated order by po.id) filter ( where po.id is not null ), '{}') :: bool[]         as emoji_animated,
+       array(select t.name
+             from poll_tag t
+                      join poll_tag_link pt on t.id = pt.tag_id
+             where pt.poll_id = p.id
+             order by t.name) :: text[]                    as tags
 from poll p
          left join poll_option po on p.id = po.poll_id
 where p.question ilike concat('%', $1 :: text, '%')
   and p.id = $2
   and p.guild_id = $3
+  and p.deleted_at is null
 group by p.id;
 
--- name: DeletePoll :exec
+-- name: SoftDeletePoll :execrows
+update poll
+set deleted_at = now()
+where id = $1
+  and guild_id = $2
+  and deleted_at is null;
+
+-- name: RestorePoll :execrows
+update poll
+set deleted_at = null
+where id = $1
+  and guild_id = $2
+  and deleted_at is not null;
+
+-- name: PurgeDeletedPollOptions :exec
+delete
+from poll_option
+where poll_id in (select id from poll where deleted_at < $1);
+
+-- name: PurgeDeletedPollPosts :exec
+delete
+from poll_post
+where poll_id in (select id from poll where deleted_at < $1);
+
+-- name: PurgeDeletedPollRevisions :exec
+delete
+from poll_revision
+where poll_id in (select id from poll where deleted_at < $1);
+
+-- name: PurgeDeletedPolls :execrows
 delete
 from poll
-where id = $1;
+where deleted_at < $1;
 
 -- name: DeletePollOptions :exec
 delete
@@ -68,4 +149,80 @@ where poll_id = $1;
 select true
 from poll
 where question = $1
-  and guild_id = $2;
\ No newline at end of file
+  and guild_id = $2
+  and deleted_at is null;
+
+-- name: FindPollIDByQuestion :one
+select id
+from poll
+where question = $1
+  and guild_id = $2
+  and deleted_at is null;
+
+-- name: UpdatePoll :exec
+update poll
+set question = $2,
+    duration = $3,
+    is_multi = $4
+where id = $1;
+
+-- name: UpdatePollVotingMethod :execrows
+update poll
+set voting_method = $3
+where id = $1
+  and guild_id = $2
+  and deleted_at is null;
+
+-- name: CreatePollAudit :exec
+insert into poll_audit(poll_id, guild_id, actor_id, action, details)
+values ($1, $2, $3, $4, $5);
+
+-- name: UpdatePollSearchText :exec
+update poll p
+set search_text = concat_ws(' ', p.question, (select string_agg(po.answer, ' ' order by po.id)
+                                              from poll_option po
+                                              where po.poll_id = p.id))
+where p.id = $1;
+
+-- name: SearchPoll :many
+with query as (select websearch_to_tsquery('simple', f_unaccent(sqlc.arg(search) :: text)) as ts,
+                      f_unaccent(lower(sqlc.arg(search) :: text))                         as text)
+select p.id,
+       p.question,
+       p.guild_id,
+       p.author_id,
+       p.is_multi,
+       p.duration,
+       p.voting_method,
+       p.created_at,
+       (select r.id
+        from poll_revision r
+        where r.poll_id = p.id
+        order by r.revision desc
+        limit 1)                                                                                                     as revision_id,
+       coalesce(array_agg(po.answer order by po.id) filter ( where po.id is not null ), '{}') :: text[]                   as answers,
+       coalesce(array_agg(coalesce(po.emoji, '') order by po.id) filter ( where po.id is not null ), '{}') :: text[]    as emoji_names,
+       coalesce(array_agg(coalesce(po.emoji_id, '') order by po.id) filter ( where po.id is not null ), '{}') :: text[] as emoji_ids,
+       coalesce(array_agg(po.emoji_animated order by po.id) filter ( where po.id is not null ), '{}') :: bool[]         as emoji_animated,
+       array(select t.name
+             from poll_tag t
+                      join poll_tag_link pt on t.id = pt.tag_id
+             where pt.poll_id = p.id
+             order by t.name) :: text[]                    as tags,
+       ts_headline('simple', p.search_text, q.ts,
+                   'MaxWords=15, MinWords=5, StartSel=**, StopSel=**') :: text as snippet
+from poll p
+         cross join query q
+         left join poll_option po on p.id = po.poll_id
+where p.guild_id = sqlc.arg(guild_id)
+  and p.deleted_at is null
+  and (p.search_vector @@ q.ts or q.text <% f_unaccent(lower(p.search_text)))
+  and (sqlc.arg(tag) :: text = '' or exists (select 1
+                                            from poll_tag_link pt
+                                                     join poll_tag t on t.id = pt.tag_id
+                                            where pt.poll_id = p.id
+                                              and t.name = sqlc.arg(tag)))
+group by p.id, q.ts, q.text
+order by greatest(ts_rank(p.search_vector, q.ts), word_similarity(q.text, f_unaccent(lower(p.search_text)))) desc,
+         p.id
+offset sqlc.arg(page_offset) limit 10;
@@ -0,0 +1,50 @@
+-- name: CreatePollVote :exec
+insert into poll_vote(post_id, user_id, answer_id)
+select pp.id, sqlc.arg(user_id), sqlc.arg(answer_id)
+from poll_post pp
+where pp.channel_id = sqlc.arg(channel_id)
+  and pp.message_id = sqlc.arg(message_id)
+  and (pp.quorum_required is not null or pp.quorum_percent is not null)
+on conflict do nothing;
+
+-- name: DeletePollVote :exec
+delete
+from poll_vote v
+    using poll_post pp
+where v.post_id = pp.id
+  and pp.channel_id = sqlc.arg(channel_id)
+  and pp.message_id = sqlc.arg(message_id)
+  and v.user_id = sqlc.arg(user_id)
+  and v.answer_id = sqlc.arg(answer_id);
+
+-- name: CountPollVoters :one
+select count(distinct user_id)
+from (select user_id
+      from poll_vote
+      where poll_vote.post_id = $1
+      union
+      select user_id
+      from custom_poll_vote
+      where custom_poll_vote.post_id = $1) voters;
+
+-- name: FindPollPostsToCheckQuorum :many
+select *
+from poll_post
+where (quorum_required is not null or quorum_percent is not null)
+  and quorum_checked_at is null
+  and closes_at <= $1
+order by closes_at
+limit 100;
+
+-- name: FinishPollPostQuorum :exec
+update poll_post
+set quorum_met        = $2,
+    quorum_required   = coalesce(quorum_required, sqlc.narg(quorum_required)),
+    quorum_checked_at = now()
+where id = $1;
+
+-- name: FailPollPostQuorum :one
+update poll_post
+set quorum_attempts = quorum_attempts + 1
+where id = $1
+returning quorum_attempts;
@@ -0,0 +1,40 @@
+-- name: CreatePollReminder :exec
+insert into poll_reminder(post_id, guild_id, channel_id, message_id, role_id, remind_at, min_turnout, created_by)
+values ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: FindPendingPollReminders :many
+select r.*, pp.poll_id
+from poll_reminder r
+         join poll_post pp on pp.id = r.post_id
+where r.guild_id = $1
+  and r.done_at is null
+order by r.remind_at
+limit 25;
+
+-- name: DeletePollReminder :execrows
+delete
+from poll_reminder
+where guild_id = $1
+  and id = $2
+  and done_at is null;
+
+-- name: FindDuePollReminders :many
+select r.*, pp.poll_id
+from poll_reminder r
+         join poll_post pp on pp.id = r.post_id
+where r.done_at is null
+  and r.remind_at <= $1
+order by r.remind_at
+limit 100;
+
+-- name: FinishPollReminder :exec
+update poll_reminder
+set done_at = now(),
+    skipped = $2
+where id = $1;
+
+-- name: FailPollReminder :one
+update poll_reminder
+set attempts = attempts + 1
+where id = $1
+returning attempts;
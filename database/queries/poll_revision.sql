@@ -0,0 +1,75 @@
+-- name: LockPollRevisions :exec
+select id
+from poll
+where id = $1
+    for update;
+
+-- name: CreatePollRevision :one
+insert into poll_revision(poll_id, revision, question, is_multi, duration, answers, emojis, author_id)
+values ($1,
+        coalesce((select max(r.revision) from poll_revision r where r.poll_id = $1), 0) + 1,
+        $2, $3, $4, $5, $6, $7)
+returning id, revision;
+
+-- name: FindPollRevisions :many
+select r.*
+from poll_revision r
+         join poll p on p.id = r.poll_id
+where r.poll_id = $1
+  and p.guild_id = $2
+  and p.deleted_at is null
+order by r.revision;
+
+-- name: FindPollRevision :one
+select r.*
+from poll_revision r
+         join poll p on p.id = r.poll_id
+where r.poll_id = $1
+  and r.revision = $2
+  and p.guild_id = $3
+  and p.deleted_at is null;
+
+-- name: CreatePollPost :one
+insert into poll_post(poll_id, revision_id, guild_id, channel_id, message_id, posted_by, batch_id, thread_id, closes_at,
+                      quorum_required, quorum_percent, quorum_role_id, quorum_repost, engine)
+values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+returning id;
+
+-- name: FindLatestPollPosts :many
+with latest as (select pp.id, pp.batch_id
+                from poll_post pp
+                         join poll p on p.id = pp.poll_id
+                where pp.poll_id = $1
+                  and p.guild_id = $2
+                  and p.deleted_at is null
+                order by pp.posted_at desc, pp.id desc
+                limit 1)
+select pp.*
+from poll_post pp
+         join latest l on pp.id = l.id or pp.batch_id = l.batch_id
+order by pp.posted_at, pp.id;
+
+-- name: FindPollPostThreadsToArchive :many
+select *
+from poll_post
+where thread_id is not null
+  and thread_archived_at is null
+  and closes_at <= $1
+order by closes_at
+limit 100;
+
+-- name: MarkPollPostThreadArchived :exec
+update poll_post
+set thread_archived_at = now()
+where id = $1;
+
+-- name: FailPollPostThreadArchive :one
+update poll_post
+set thread_archive_attempts = thread_archive_attempts + 1
+where id = $1
+returning thread_archive_attempts;
+
+-- name: CountPollPosts :one
+select count(*)
+from poll_post
+where poll_id = $1;
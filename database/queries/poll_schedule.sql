@@ -0,0 +1,39 @@
+-- name: FindRandomPollCandidates :many
+select p.id, l.weight
+from poll p
+         join poll_tag_link l on l.poll_id = p.id
+         join poll_tag t on t.id = l.tag_id
+where p.guild_id = $1
+  and t.name = $2
+  and p.deleted_at is null
+  and not exists (select 1
+                  from poll_post pp
+                  where pp.poll_id = p.id
+                    and pp.posted_at > $3)
+order by p.id;
+
+-- name: SavePollSchedule :exec
+insert into poll_schedule(guild_id, channel_id, tag, hour, avoid_days, next_run_at, created_by)
+values ($1, $2, $3, $4, $5, $6, $7)
+on conflict (guild_id, channel_id) do update set tag         = excluded.tag,
+                                                 hour        = excluded.hour,
+                                                 avoid_days  = excluded.avoid_days,
+                                                 next_run_at = excluded.next_run_at,
+                                                 created_by  = excluded.created_by;
+
+-- name: DeletePollSchedule :execrows
+delete
+from poll_schedule
+where guild_id = $1
+  and channel_id = $2;
+
+-- name: FindDuePollSchedules :many
+select *
+from poll_schedule
+where next_run_at <= $1
+order by next_run_at;
+
+-- name: UpdatePollScheduleNextRun :exec
+update poll_schedule
+set next_run_at = $2
+where id = $1;
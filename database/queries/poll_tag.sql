@@ -0,0 +1,69 @@
+-- name: UpsertPollTag :one
+insert into poll_tag(guild_id, name)
+values ($1, $2)
+on conflict (guild_id, name) do update set name = excluded.name
+returning id;
+
+-- name: AddPollTag :execrows
+insert into poll_tag_link(poll_id, tag_id, weight)
+select p.id, $3, $4
+from poll p
+where p.id = $1
+  and p.guild_id = $2
+  and p.deleted_at is null
+on conflict (poll_id, tag_id) do update set weight = excluded.weight;
+
+-- name: RemovePollTag :execrows
+delete
+from poll_tag_link pt
+    using poll_tag t, poll p
+where pt.tag_id = t.id
+  and pt.poll_id = p.id
+  and p.id = $1
+  and p.guild_id = $2
+  and t.name = $3;
+
+-- name: FindGuildPollTags :many
+select t.name
+from poll_tag t
+where t.guild_id = sqlc.arg(guild_id)
+  and starts_with(t.name, sqlc.arg(prefix))
+  and exists (select 1
+              from poll_tag_link pt
+                       join poll p on p.id = pt.poll_id
+              where pt.tag_id = t.id
+                and p.deleted_at is null)
+order by t.name
+limit 25;
+
+-- name: FindGuildPollsToExport :many
+select p.id,
+       p.question,
+       p.is_multi,
+       p.duration,
+       p.voting_method,
+       coalesce(array_agg(po.answer order by po.id) filter ( where po.id is not null ), '{}') :: text[]                   as answers,
+       coalesce(array_agg(coalesce(po.emoji, '') order by po.id) filter ( where po.id is not null ), '{}') :: text[]    as emoji_names,
+       coalesce(array_agg(coalesce(po.emoji_id, '') order by po.id) filter ( where po.id is not null ), '{}') :: text[] as emoji_ids,
+       coalesce(array_agg(po.emoji_animated order by po.id) filter ( where po.id is not null ), '{}') :: bool[]         as emoji_animated,
+       array(select t.name
+             from poll_tag t
+                      join poll_tag_link pt on t.id = pt.tag_id
+             where pt.poll_id = p.id
+             order by t.name) :: text[]                                                                                as tags,
+       array(select pt.weight
+             from poll_tag t
+                      join poll_tag_link pt on t.id = pt.tag_id
+             where pt.poll_id = p.id
+             order by t.name) :: int2[]                                                                                as tag_weights
+from poll p
+         left join poll_option po on p.id = po.poll_id
+where p.guild_id = sqlc.arg(guild_id)
+  and p.deleted_at is null
+  and (sqlc.arg(tag) :: text = '' or exists (select 1
+                                            from poll_tag_link pt
+                                                     join poll_tag t on t.id = pt.tag_id
+                                            where pt.poll_id = p.id
+                                              and t.name = sqlc.arg(tag)))
+group by p.id
+order by p.id;
@@ -0,0 +1,406 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/wittano/yomoid/poll"
+	"github.com/wittano/yomoid/settings"
+)
+
+const (
+	configCommandGroupName           = "config"
+	configViewCommandName            = "view"
+	configLinkFixingCommandName      = "link-fixing"
+	configCaptureModeCommandName     = "capture-mode"
+	configCaptureChannelCommandName  = "capture-channel"
+	configCaptureRoleCommandName     = "capture-role"
+	configCaptureQuietCommandName    = "capture-quiet"
+	configAdminRoleCommandName       = "admin-role"
+	configDefaultDurationCommandName = "default-duration"
+	configPollThreadsCommandName     = "poll-threads"
+)
+
+const (
+	listActionAdd    = "add"
+	listActionRemove = "remove"
+	listActionClear  = "clear"
+)
+
+// CommandGroup routes subcommands nested in subcommand group
+type CommandGroup map[string]SlashCommandHandler
+
+func (g CommandGroup) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	group := i.ApplicationCommandData().Options[0]
+	if len(group.Options) == 0 {
+		return nil, fmt.Errorf("discord: missing subcommand in group %q", group.Name)
+	}
+
+	handler, ok := g[group.Options[0].Name]
+	if !ok {
+		return nil, fmt.Errorf("discord: unknown subcommand %q in group %q", group.Options[0].Name, group.Name)
+	}
+
+	return handler.HandleSlashCommand(ctx, l, s, i)
+}
+
+func (g CommandGroup) Deferred(i *discordgo.InteractionCreate) bool {
+	group := i.ApplicationCommandData().Options[0]
+	if len(group.Options) == 0 {
+		return false
+	}
+
+	handler, ok := g[group.Options[0].Name]
+	return ok && isDeferred(handler, i)
+}
+
+func NewYomoidCommand(guildSettings *settings.Service) Command {
+	return map[string]SlashCommandHandler{
+		configCommandGroupName: CommandGroup{
+			configViewCommandName: ConfigViewCommand{Settings: guildSettings},
+			configLinkFixingCommandName: ConfigSetCommand{Settings: guildSettings, Apply: func(s *settings.Settings, args map[string]any) error {
+				s.LinkFixing, _ = args["enabled"].(bool)
+				return nil
+			}},
+			configCaptureModeCommandName: ConfigSetCommand{Settings: guildSettings, Apply: func(s *settings.Settings, args map[string]any) error {
+				mode, _ := args["mode"].(string)
+				if !settings.CaptureMode(mode).Valid() {
+					return MessageErr{CommandName: "config", Msg: fmt.Sprintf("Unknown capture mode %q", mode)}
+				}
+
+				s.CaptureMode = settings.CaptureMode(mode)
+				return nil
+			}},
+			configCaptureChannelCommandName: ConfigSetCommand{Settings: guildSettings, Apply: func(s *settings.Settings, args map[string]any) (err error) {
+				s.CaptureChannels, err = applyListAction(s.CaptureChannels, args, "channel")
+				return
+			}},
+			configCaptureRoleCommandName: ConfigSetCommand{Settings: guildSettings, Apply: func(s *settings.Settings, args map[string]any) (err error) {
+				s.CaptureRoles, err = applyListAction(s.CaptureRoles, args, "role")
+				return
+			}},
+			configCaptureQuietCommandName: ConfigSetCommand{Settings: guildSettings, Apply: func(s *settings.Settings, args map[string]any) error {
+				s.CaptureQuiet, _ = args["enabled"].(bool)
+				return nil
+			}},
+			configAdminRoleCommandName: ConfigSetCommand{Settings: guildSettings, Apply: func(s *settings.Settings, args map[string]any) error {
+				s.AdminRoleID, _ = args["role"].(string)
+				return nil
+			}},
+			configDefaultDurationCommandName: ConfigSetCommand{Settings: guildSettings, Apply: func(s *settings.Settings, args map[string]any) error {
+				hours, ok := args["hours"].(float64)
+				if !ok {
+					return MessageErr{CommandName: "config", Msg: "Missing required hours argument"}
+				}
+
+				s.DefaultDuration = int16(hours)
+				return nil
+			}},
+			configPollThreadsCommandName: ConfigSetCommand{Settings: guildSettings, Apply: func(s *settings.Settings, args map[string]any) error {
+				s.PollThreads, _ = args["enabled"].(bool)
+				return nil
+			}},
+		},
+	}
+}
+
+// applyListAction adds, removes ID passed in argName argument or clears whole list
+func applyListAction(list []string, args map[string]any, argName string) ([]string, error) {
+	action, _ := args["action"].(string)
+	id, _ := args[argName].(string)
+
+	if action != listActionClear && id == "" {
+		return nil, MessageErr{CommandName: "config", Msg: fmt.Sprintf("Missing required %s argument", argName)}
+	}
+
+	switch action {
+	case listActionAdd:
+		if !slices.Contains(list, id) {
+			list = append(list, id)
+		}
+	case listActionRemove:
+		list = slices.DeleteFunc(list, func(v string) bool {
+			return v == id
+		})
+	case listActionClear:
+		list = nil
+	default:
+		return nil, MessageErr{CommandName: "config", Msg: fmt.Sprintf("Unknown action %q", action)}
+	}
+
+	return list, nil
+}
+
+// isGuildAdmin checks if member can manage guild or has bot's admin role
+func isGuildAdmin(member *discordgo.Member, s settings.Settings) bool {
+	if member == nil {
+		return false
+	}
+
+	if member.Permissions&(discordgo.PermissionAdministrator|discordgo.PermissionManageGuild) != 0 {
+		return true
+	}
+
+	return s.AdminRoleID != "" && slices.Contains(member.Roles, s.AdminRoleID)
+}
+
+type ConfigViewCommand struct {
+	Settings *settings.Service
+}
+
+func (c ConfigViewCommand) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	guildSettings, err := c.Settings.Get(ctx, i.GuildID)
+	if err != nil {
+		return nil, err
+	}
+
+	return createSettingsResponse(guildSettings), nil
+}
+
+type ConfigSetCommand struct {
+	Settings *settings.Service
+	Apply    func(s *settings.Settings, args map[string]any) error
+}
+
+func (c ConfigSetCommand) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	current, err := c.Settings.Get(ctx, i.GuildID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isGuildAdmin(i.Member, current) {
+		return nil, MessageErr{CommandName: "config", Msg: "You don't have permission to change bot settings"}
+	}
+
+	args := parseInteractionInput(*i.Interaction)
+	updated, err := c.Settings.Update(ctx, i.GuildID, func(s *settings.Settings) error {
+		return c.Apply(s, args)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	l.InfoContext(ctx, "guild settings updated", "settings", fmt.Sprintf("%+v", updated))
+
+	return createSettingsResponse(updated), nil
+}
+
+func mentionList(ids []string, prefix, empty string) string {
+	if len(ids) == 0 {
+		return empty
+	}
+
+	mentions := make([]string, len(ids))
+	for i, id := range ids {
+		mentions[i] = "<" + prefix + id + ">"
+	}
+
+	return strings.Join(mentions, ", ")
+}
+
+func createSettingsResponse(s settings.Settings) *discordgo.InteractionResponse {
+	channels := mentionList(s.CaptureChannels, "#", "none")
+	if s.CaptureMode == settings.CaptureEverywhere {
+		channels = "all channels"
+	}
+
+	adminRole := "not set"
+	if s.AdminRoleID != "" {
+		adminRole = "<@&" + s.AdminRoleID + ">"
+	}
+
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{
+				{
+					Title: "Yomoid settings",
+					Fields: []*discordgo.MessageEmbedField{
+						{Name: "9gag link fixing", Value: enabledText(s.LinkFixing), Inline: true},
+						{Name: "Poll capture mode", Value: string(s.CaptureMode), Inline: true},
+						{Name: "Quiet capture", Value: enabledText(s.CaptureQuiet), Inline: true},
+						{Name: "Captured channels", Value: channels},
+						{Name: "Captured roles", Value: mentionList(s.CaptureRoles, "@&", "all members")},
+						{Name: "Admin role", Value: adminRole, Inline: true},
+						{Name: "Default poll duration", Value: time.Duration(int64(s.DefaultDuration) * int64(time.Hour)).String(), Inline: true},
+						{Name: "Poll discussion threads", Value: enabledText(s.PollThreads), Inline: true},
+					},
+				},
+			},
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	}
+}
+
+func enabledText(enabled bool) string {
+	if enabled {
+		return "enabled"
+	}
+
+	return "disabled"
+}
+
+func pollDurationChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, len(poll.Durations))
+	for i, h := range poll.Durations {
+		choices[i] = &discordgo.ApplicationCommandOptionChoice{
+			Name:  time.Duration(int64(h) * int64(time.Hour)).String(),
+			Value: h,
+		}
+	}
+
+	return choices
+}
+
+func listActionOption() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "action",
+		Required:    true,
+		Description: "Add, remove item or clear list",
+		Choices: []*discordgo.ApplicationCommandOptionChoice{
+			{Name: listActionAdd, Value: listActionAdd},
+			{Name: listActionRemove, Value: listActionRemove},
+			{Name: listActionClear, Value: listActionClear},
+		},
+	}
+}
+
+func NewYomoidCommandDefinition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        "yomoid",
+		Description: "Manage bot",
+		Type:        discordgo.ChatApplicationCommand,
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+				Name:        configCommandGroupName,
+				Description: "Show or change bot settings for this server",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        configViewCommandName,
+						Description: "Show current settings",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        configLinkFixingCommandName,
+						Description: "Enable or disable fixing 9gag links",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionBoolean,
+								Name:        "enabled",
+								Required:    true,
+								Description: "Fix 9gag links",
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        configCaptureModeCommandName,
+						Description: "Choose where native polls are saved as templates",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "mode",
+								Required:    true,
+								Description: "Capture mode",
+								Choices: []*discordgo.ApplicationCommandOptionChoice{
+									{Name: "off", Value: string(settings.CaptureOff)},
+									{Name: "allow-listed channels only", Value: string(settings.CaptureAllowList)},
+									{Name: "everywhere", Value: string(settings.CaptureEverywhere)},
+								},
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        configCaptureChannelCommandName,
+						Description: "Change allow-list of channels, where polls are captured",
+						Options: []*discordgo.ApplicationCommandOption{
+							listActionOption(),
+							{
+								Type: discordgo.ApplicationCommandOptionChannel,
+								Name: "channel",
+								ChannelTypes: []discordgo.ChannelType{
+									discordgo.ChannelTypeGuildText,
+								},
+								Description: "Text channel",
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        configCaptureRoleCommandName,
+						Description: "Change roles, which polls are captured. Empty list means all members",
+						Options: []*discordgo.ApplicationCommandOption{
+							listActionOption(),
+							{
+								Type:        discordgo.ApplicationCommandOptionRole,
+								Name:        "role",
+								Description: "Poll author's role",
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        configCaptureQuietCommandName,
+						Description: "React with emoji on captured poll instead of replying",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionBoolean,
+								Name:        "enabled",
+								Required:    true,
+								Description: "Quiet mode",
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        configAdminRoleCommandName,
+						Description: "Set role, which can manage bot. Skip role to unset it",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionRole,
+								Name:        "role",
+								Description: "Admin role",
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        configDefaultDurationCommandName,
+						Description: "Set default duration of saved polls",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionInteger,
+								Name:        "hours",
+								Required:    true,
+								Description: "Poll duration",
+								Choices:     pollDurationChoices(),
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        configPollThreadsCommandName,
+						Description: "Start discussion thread from every poll posted by /poll create",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionBoolean,
+								Name:        "enabled",
+								Required:    true,
+								Description: "Start threads by default",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,806 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/wittano/yomoid/poll"
+	"github.com/wittano/yomoid/settings"
+	"github.com/wittano/yomoid/tracing"
+)
+
+const (
+	customPollVoteID   = "cpoll-vote"
+	customPollSelectID = "cpoll-select"
+	customPollVotersID = "cpoll-voters"
+)
+
+const (
+	// maxButtonAnswers is limit of answers rendered as buttons. Last row of message is kept for controls
+	maxButtonAnswers = 20
+	buttonsPerRow    = 5
+	maxButtonLabel   = 80
+	tallyBarWidth    = 10
+	// maxCustomPollHours is the longest duration, which can be represented by time.Duration. Poll posted with buttons
+	// engine isn't limited like native poll
+	maxCustomPollHours = math.MaxInt64 / int64(time.Hour)
+)
+
+var errTooManyAnswers = errors.New("poll: too many answers")
+
+// createPollMessage renders template with engine chosen in request. Custom poll is nil for native engine
+func createPollMessage(p poll.Model, vars poll.Variables, req postRequest, closesAt time.Time) (*discordgo.MessageSend, *poll.CustomPoll, error) {
+	if req.Engine != poll.EngineButtons {
+		dp, err := createDiscordPoll(p, vars)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return &discordgo.MessageSend{Poll: &dp}, nil, nil
+	}
+
+	question, answers, err := renderPoll(p, vars)
+	if err != nil {
+		return nil, nil, err
+	} else if len(answers) > poll.MaxCustomAnswers {
+		return nil, nil, fmt.Errorf("%w: poll can have up to %d answers", errTooManyAnswers, poll.MaxCustomAnswers)
+	}
+
+	cp := poll.CustomPoll{
+		PollID:      p.ID,
+		GuildID:     req.GuildID,
+		ChannelID:   req.Channel.ID,
+		Question:    question,
+		Answers:     answers,
+		IsMulti:     p.IsMulti || p.Method == poll.Approval,
+		Method:      p.Method,
+		Anonymous:   req.Anonymous,
+		VoterRoleID: req.VoterRoleID,
+		ClosesAt:    closesAt,
+		Weights:     req.Weights,
+		AllowOther:  req.AllowOther,
+	}
+
+	return &discordgo.MessageSend{
+		Embeds:          []*discordgo.MessageEmbed{createCustomPollEmbed(cp, poll.Tally{}, vars.Now)},
+		Components:      createCustomPollComponents(cp, vars.Now),
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	}, &cp, nil
+}
+
+// parseEngineArgs reads engine options of /poll create. Empty engine is resolved by voting method of template
+func parseEngineArgs(args map[string]any, req *postRequest) error {
+	engine, _ := args["engine"].(string)
+	req.Engine = poll.Engine(engine)
+	if req.Engine != "" && !req.Engine.Valid() {
+		return MessageErr{CommandName: "post", Msg: "Unknown poll engine " + engine}
+	}
+
+	hours, _ := args["hours"].(float64)
+	if hours > float64(maxCustomPollHours) {
+		return MessageErr{CommandName: "post", Msg: fmt.Sprintf("Poll can't last longer than %d hours", maxCustomPollHours)}
+	}
+	req.Hours = int(hours)
+	req.Anonymous, _ = args["anonymous"].(bool)
+	req.VoterRoleID, _ = args["voter-role"].(string)
+	req.AllowOther, _ = args["other"].(bool)
+
+	return nil
+}
+
+// createCustomPollEmbed renders question and live tally of poll posted with buttons engine
+func createCustomPollEmbed(cp poll.CustomPoll, t poll.Tally, now time.Time) *discordgo.MessageEmbed {
+	var b strings.Builder
+	for i, a := range cp.Answers {
+		votes := 0
+		if i < len(t.Votes) {
+			votes = t.Votes[i]
+		}
+
+		if len(cp.Weights) == 0 {
+			fmt.Fprintf(&b, "%s\n`%s` %d (%d%%)\n", customAnswerLabel(a), tallyBar(votes, t.Total), votes, percent(votes, t.Total))
+			continue
+		}
+
+		weighted := 0
+		if i < len(t.Weighted) {
+			weighted = t.Weighted[i]
+		}
+		fmt.Fprintf(&b, "%s\n`%s` %d weighted (%d%%), %d votes\n", customAnswerLabel(a), tallyBar(weighted, t.WeightedTotal),
+			weighted, percent(weighted, t.WeightedTotal), votes)
+	}
+
+	status := fmt.Sprintf("Closes <t:%d:R>", cp.ClosesAt.Unix())
+	if !cp.IsOpen(now) {
+		status = fmt.Sprintf("Closed <t:%d:R>", cp.ClosesAt.Unix())
+	}
+	if cp.VoterRoleID != "" {
+		status += fmt.Sprintf("\nOnly <@&%s> can vote", cp.VoterRoleID)
+	}
+	if len(cp.Weights) > 0 {
+		status += "\nWeights: " + poll.FormatRoleWeights(cp.Weights)
+	}
+	b.WriteString("\n" + status)
+
+	footer := fmt.Sprintf("%d voters", t.Total)
+	switch {
+	case cp.Method == poll.Ranked:
+		footer += " · ranked choice, tally shows first choices"
+	case cp.Method == poll.Approval:
+		footer += " · approval voting"
+	case cp.IsMulti:
+		footer += " · multiple answers"
+	}
+	if cp.Anonymous {
+		footer += " · anonymous"
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       cp.Question,
+		Description: b.String(),
+		Footer:      &discordgo.MessageEmbedFooter{Text: footer},
+	}
+}
+
+func customAnswerLabel(a poll.Answer) string {
+	if a.Emoji.IsZero() {
+		return "**" + a.Text + "**"
+	}
+
+	return a.Emoji.String() + " **" + a.Text + "**"
+}
+
+func percent(votes, total int) int {
+	if total == 0 {
+		return 0
+	}
+
+	return votes * 100 / total
+}
+
+func tallyBar(votes, total int) string {
+	filled := 0
+	if total > 0 {
+		filled = votes * tallyBarWidth / total
+	}
+
+	return strings.Repeat("▓", filled) + strings.Repeat("░", tallyBarWidth-filled)
+}
+
+// createCustomPollComponents renders answers as buttons or, for many answers, as select menu. Closed poll doesn't
+// have components
+func createCustomPollComponents(cp poll.CustomPoll, now time.Time) []discordgo.MessageComponent {
+	components := []discordgo.MessageComponent{}
+	if !cp.IsOpen(now) {
+		return components
+	}
+
+	if cp.Method == poll.Ranked {
+		components = append(components, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "Rank answers",
+					Style:    discordgo.SuccessButton,
+					CustomID: customPollRankID,
+				},
+			},
+		})
+	} else if len(cp.Answers) <= maxButtonAnswers {
+		var row discordgo.ActionsRow
+		for i, a := range cp.Answers {
+			button := discordgo.Button{
+				Label:    truncate(a.Text, maxButtonLabel),
+				Style:    discordgo.SecondaryButton,
+				CustomID: customID(customPollVoteID, strconv.Itoa(i)),
+				Emoji:    a.Emoji.Component(),
+			}
+
+			row.Components = append(row.Components, button)
+			if len(row.Components) == buttonsPerRow {
+				components = append(components, row)
+				row = discordgo.ActionsRow{}
+			}
+		}
+		if len(row.Components) > 0 {
+			components = append(components, row)
+		}
+	} else {
+		options := make([]discordgo.SelectMenuOption, len(cp.Answers))
+		for i, a := range cp.Answers {
+			options[i] = discordgo.SelectMenuOption{Label: a.Text, Value: strconv.Itoa(i), Emoji: a.Emoji.Component()}
+		}
+
+		maxValues := 1
+		if cp.IsMulti {
+			maxValues = len(options)
+		}
+
+		components = append(components, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					MenuType:    discordgo.StringSelectMenu,
+					CustomID:    customPollSelectID,
+					Placeholder: "Choose answer. Clear selection to remove vote",
+					MinValues:   new(int),
+					MaxValues:   maxValues,
+					Options:     options,
+				},
+			},
+		})
+	}
+
+	var controls []discordgo.MessageComponent
+	if cp.AllowOther {
+		controls = append(controls,
+			discordgo.Button{Label: "Other…", Style: discordgo.SecondaryButton, CustomID: customPollOtherID},
+			discordgo.Button{Label: "Other answers", Style: discordgo.PrimaryButton, CustomID: customPollRepliesID},
+		)
+	}
+	if !cp.Anonymous {
+		controls = append(controls, discordgo.Button{Label: "Voters", Style: discordgo.PrimaryButton, CustomID: customPollVotersID})
+	}
+	if len(controls) > 0 {
+		components = append(components, discordgo.ActionsRow{Components: controls})
+	}
+
+	return components
+}
+
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	return string([]rune(s)[:n-1]) + "…"
+}
+
+// customPollResults renders custom poll as Discord's poll, so results and reminders treat both engines the same way
+func customPollResults(cp poll.CustomPoll, t poll.Tally, now time.Time) *discordgo.Poll {
+	p := &discordgo.Poll{
+		Question:         discordgo.PollMedia{Text: cp.Question},
+		AllowMultiselect: cp.IsMulti,
+		Expiry:           &cp.ClosesAt,
+		Results:          &discordgo.PollResults{Finalized: !cp.IsOpen(now)},
+	}
+
+	for i, a := range cp.Answers {
+		answer := discordgo.PollAnswer{AnswerID: i + 1, Media: &discordgo.PollMedia{Text: a.Text, Emoji: a.Emoji.Component()}}
+		p.Answers = append(p.Answers, answer)
+
+		votes := 0
+		if i < len(t.Votes) {
+			votes = t.Votes[i]
+		}
+		p.Results.AnswerCounts = append(p.Results.AnswerCounts, &discordgo.PollAnswerCount{ID: i + 1, Count: votes})
+	}
+
+	return p
+}
+
+// fetchPollMessage returns posted poll. Votes of poll posted with buttons engine are read from database
+func fetchPollMessage(ctx context.Context, s *discordgo.Session, db poll.Queries, channelID, messageID string) (*discordgo.Message, error) {
+	msg, err := s.ChannelMessage(channelID, messageID, discordgo.WithContext(ctx))
+	if err != nil || msg.Poll != nil {
+		return msg, err
+	}
+
+	cp, err := db.FindCustomPoll(ctx, channelID, messageID)
+	if errors.Is(err, poll.ErrCustomPollNotFound) {
+		return msg, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	t, err := db.FindTally(ctx, cp.PostID, len(cp.Answers))
+	if err != nil {
+		return nil, err
+	}
+	msg.Poll = customPollResults(cp, t, time.Now())
+
+	return msg, nil
+}
+
+// findOpenCustomPoll returns poll posted in message, if user can vote in it
+func findOpenCustomPoll(ctx context.Context, db poll.Queries, i *discordgo.InteractionCreate, messageID string) (poll.CustomPoll, error) {
+	cp, err := db.FindCustomPoll(ctx, i.ChannelID, messageID)
+	if errors.Is(err, poll.ErrCustomPollNotFound) {
+		return cp, MessageErr{error: err, CommandName: "vote", Msg: "This poll doesn't exist anymore"}
+	} else if err != nil {
+		return cp, err
+	}
+
+	if !cp.IsOpen(time.Now()) {
+		return cp, MessageErr{CommandName: "vote", Msg: "This poll is closed"}
+	}
+
+	if cp.VoterRoleID != "" && (i.Member == nil || !hasRole(i.Member, cp.GuildID, cp.VoterRoleID)) {
+		return cp, MessageErr{CommandName: "vote", Msg: fmt.Sprintf("Only members with <@&%s> role can vote in this poll", cp.VoterRoleID)}
+	}
+
+	return cp, nil
+}
+
+// saveCustomVotes replaces user's votes by answers or by result of toggle and re-renders message with new tally
+func saveCustomVotes(ctx context.Context, l *slog.Logger, db poll.Queries, i *discordgo.InteractionCreate, cp poll.CustomPoll, answers []int, toggle func([]int) []int) (*discordgo.InteractionResponse, error) {
+	votes, err := db.SetVotes(ctx, poll.VoteParams{
+		PostID:  cp.PostID,
+		UserID:  interactionUserID(i),
+		Answers: answers,
+		Ranked:  cp.Method == poll.Ranked,
+		Weight:  voterWeight(cp, i),
+		// Vote for fixed answer replaces other answer
+		ClearReply: cp.AllowOther && singleChoice(cp),
+		Toggle:     toggle,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	l.InfoContext(ctx, "custom poll vote saved", "postID", cp.PostID, "votes", votes)
+
+	t, err := db.FindTally(ctx, cp.PostID, len(cp.Answers))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{createCustomPollEmbed(cp, t, now)},
+			Components: createCustomPollComponents(cp, now),
+		},
+	}, nil
+}
+
+// CustomPollVoteButton toggles user's vote for answer of clicked button
+type CustomPollVoteButton struct {
+	Db poll.Queries
+}
+
+func (b CustomPollVoteButton) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	cp, err := findOpenCustomPoll(ctx, b.Db, i, i.Message.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, args := parseCustomID(i.MessageComponentData().CustomID)
+	if len(args) != 1 {
+		return nil, fmt.Errorf("vote: invalid custom ID %q", i.MessageComponentData().CustomID)
+	}
+
+	answer, err := strconv.Atoi(args[0])
+	if err != nil || answer < 0 || answer >= len(cp.Answers) {
+		return nil, fmt.Errorf("vote: invalid answer %q", args[0])
+	}
+
+	return saveCustomVotes(ctx, l, b.Db, i, cp, nil, func(current []int) []int {
+		return poll.ToggleVote(current, answer, cp.IsMulti)
+	})
+}
+
+// CustomPollSelect replaces user's votes by answers chosen in select menu
+type CustomPollSelect struct {
+	Db poll.Queries
+}
+
+func (c CustomPollSelect) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	cp, err := findOpenCustomPoll(ctx, c.Db, i, i.Message.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	values := i.MessageComponentData().Values
+	if !cp.IsMulti && len(values) > 1 {
+		return nil, MessageErr{CommandName: "vote", Msg: "This poll allows only one answer"}
+	}
+
+	votes := make([]int, 0, len(values))
+	for _, v := range values {
+		answer, err := strconv.Atoi(v)
+		if err != nil || answer < 0 || answer >= len(cp.Answers) {
+			return nil, fmt.Errorf("vote: invalid answer %q", v)
+		}
+		votes = append(votes, answer)
+	}
+	slices.Sort(votes)
+
+	return saveCustomVotes(ctx, l, c.Db, i, cp, slices.Compact(votes), nil)
+}
+
+// CustomPollVotersButton shows who voted for answers of public poll
+type CustomPollVotersButton struct {
+	Db poll.Queries
+}
+
+func (b CustomPollVotersButton) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	cp, err := b.Db.FindCustomPoll(ctx, i.ChannelID, i.Message.ID)
+	if errors.Is(err, poll.ErrCustomPollNotFound) {
+		return nil, MessageErr{error: err, CommandName: "voters", Msg: "This poll doesn't exist anymore"}
+	} else if err != nil {
+		return nil, err
+	} else if cp.Anonymous {
+		return nil, MessageErr{CommandName: "voters", Msg: "Voters of this poll are anonymous"}
+	}
+
+	t, err := b.Db.FindTally(ctx, cp.PostID, len(cp.Answers))
+	if err != nil {
+		return nil, err
+	}
+
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{
+				{
+					Title:       cp.Question,
+					Description: votersList(cp, t),
+				},
+			},
+			Flags:           discordgo.MessageFlagsEphemeral,
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	}, nil
+}
+
+// maxEmbedDescription is Discord's limit of embed description
+const maxEmbedDescription = 4096
+
+func votersList(cp poll.CustomPoll, t poll.Tally) string {
+	var b strings.Builder
+	for i, a := range cp.Answers {
+		mentions := "nobody"
+		if i < len(t.Voters) && len(t.Voters[i]) > 0 {
+			users := make([]string, len(t.Voters[i]))
+			for j, id := range t.Voters[i] {
+				users[j] = "<@" + id + ">"
+			}
+			mentions = strings.Join(users, ", ")
+		}
+
+		fmt.Fprintf(&b, "%s: %s\n", customAnswerLabel(a), mentions)
+	}
+
+	return truncate(b.String(), maxEmbedDescription)
+}
+
+// CustomPollCloser shows final tally and removes buttons of polls posted with buttons engine after they close
+type CustomPollCloser struct {
+	Db       poll.Queries
+	Session  *discordgo.Session
+	Interval time.Duration
+	// Timeout of closing single poll
+	Timeout time.Duration
+}
+
+// Run blocks until ctx is cancelled
+func (c CustomPollCloser) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.closeDue(ctx)
+		}
+	}
+}
+
+func (c CustomPollCloser) closeDue(ctx context.Context) {
+	polls, err := c.Db.FindCustomPollsToClose(ctx, time.Now())
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			slog.ErrorContext(ctx, "failed find custom polls to close", "error", err)
+		}
+		return
+	}
+
+	for _, cp := range polls {
+		if ctx.Err() != nil {
+			return
+		}
+
+		c.close(ctx, cp)
+	}
+}
+
+func (c CustomPollCloser) close(ctx context.Context, cp poll.CustomPoll) {
+	ctx, span := tracing.StartEvent(ctx, "job custom poll close", cp.GuildID, cp.ChannelID)
+	defer span.End()
+
+	l := slog.Default().With("pollID", cp.PollID, "postID", cp.PostID, "guildID", cp.GuildID, "channelID", cp.ChannelID, "messageID", cp.MessageID)
+
+	t, err := c.showFinalTally(ctx, cp)
+	if err != nil && !isPermanentRESTErr(err) {
+		l.WarnContext(ctx, "failed show final tally of custom poll", "error", err)
+		tracing.RecordError(span, err)
+		if !c.giveUp(ctx, l, cp) {
+			return
+		}
+	} else if err != nil {
+		// Removed message or missing permissions won't change in next run
+		l.WarnContext(ctx, "final tally of custom poll can't be shown", "error", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	if err = c.Db.CloseCustomPoll(ctx, cp.PostID); err != nil {
+		l.ErrorContext(ctx, "failed mark custom poll as closed", "error", err)
+		tracing.RecordError(span, err)
+		return
+	}
+
+	l.InfoContext(ctx, "custom poll closed", "voters", t.Total)
+}
+
+// showFinalTally replaces buttons of closed poll with final tally
+func (c CustomPollCloser) showFinalTally(ctx context.Context, cp poll.CustomPoll) (poll.Tally, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	t, err := c.Db.FindTally(ctx, cp.PostID, len(cp.Answers))
+	if err != nil {
+		return poll.Tally{}, fmt.Errorf("find votes: %w", err)
+	}
+
+	cp.Closed = true
+	embed := createCustomPollEmbed(cp, t, time.Now())
+	if cp.Method == poll.Ranked {
+		ballots, err := c.Db.FindBallots(ctx, []int64{cp.PostID})
+		if err != nil {
+			return t, fmt.Errorf("find ballots of ranked poll: %w", err)
+		}
+
+		embed.Description += "\n\n" + runoffSummary(cp.Answers, poll.InstantRunoff(len(cp.Answers), ballots))
+	}
+
+	embeds := []*discordgo.MessageEmbed{embed}
+	components := []discordgo.MessageComponent{}
+	_, err = c.Session.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		ID:         cp.MessageID,
+		Channel:    cp.ChannelID,
+		Embeds:     &embeds,
+		Components: &components,
+	}, discordgo.WithContext(ctx))
+
+	return t, err
+}
+
+// giveUp counts failed closing of poll. It reports, if poll should be closed without final tally after
+// maxJobAttempts failures, so it doesn't block closing next polls
+func (c CustomPollCloser) giveUp(ctx context.Context, l *slog.Logger, cp poll.CustomPoll) bool {
+	// Closing interrupted by shutdown isn't counted
+	if ctx.Err() != nil {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	attempts, err := c.Db.FailCustomPollClose(ctx, cp.PostID)
+	if err != nil {
+		l.ErrorContext(ctx, "failed count failed closing of custom poll", "error", err)
+		return false
+	}
+
+	return attempts >= maxJobAttempts
+}
+
+var (
+	minCustomPollHours float64 = 1
+	answerPosition     float64 = 1
+)
+
+func engineOptions() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "engine",
+			Description: "Post as Discord's poll or as message with buttons, which supports more answers",
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "native poll", Value: string(poll.EngineNative)},
+				{Name: "buttons", Value: string(poll.EngineButtons)},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "hours",
+			MinValue:    &minCustomPollHours,
+			Description: "Duration of poll posted with buttons engine. Template's duration is used by default",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "anonymous",
+			Description: "Hide voters of poll posted with buttons engine",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionRole,
+			Name:        "voter-role",
+			Description: "Only members with role can vote in poll posted with buttons engine",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "other",
+			Description: "Let voters type their own answer in poll posted with buttons engine",
+		},
+	}
+}
+
+const (
+	pollAnswerCommandGroupName = "answer"
+	answerAddCommandName       = "add"
+	answerRemoveCommandName    = "remove"
+)
+
+// PollAnswerCommand adds or removes answer of template. Templates with more than 10 answers can be posted only with
+// buttons engine
+type PollAnswerCommand struct {
+	Db       poll.Queries
+	Settings *settings.Service
+	Add      bool
+}
+
+func (c PollAnswerCommand) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	guildSettings, err := c.Settings.Get(ctx, i.GuildID)
+	if err != nil {
+		return nil, err
+	} else if !isGuildAdmin(i.Member, guildSettings) {
+		return nil, MessageErr{CommandName: "answer", Msg: "Only moderators can change answers of poll template"}
+	}
+
+	args := parseInteractionInput(*i.Interaction)
+	id, _ := args["id"].(float64)
+	text, _ := args["text"].(string)
+	emoji, _ := args["emoji"].(string)
+
+	added := poll.AnswerParams{Text: strings.TrimSpace(text), Emoji: poll.ParseEmoji(emoji)}
+	if err := checkEmojis(ctx, s, i.GuildID, []poll.Answer{poll.Answer(added)}); errors.Is(err, errUnknownEmoji) {
+		return nil, MessageErr{error: err, CommandName: "answer", Msg: "Emoji must be unicode emoji or emoji of this server"}
+	} else if err != nil {
+		return nil, err
+	}
+
+	summary, err := changeTemplateAnswers(ctx, l, c.Db, i.GuildID, int64(id), interactionUserID(i), func(answers []poll.AnswerParams) ([]poll.AnswerParams, string, error) {
+		if c.Add {
+			return addTemplateAnswer(answers, added)
+		}
+
+		position, _ := args["position"].(float64)
+		switch {
+		case int(position) > len(answers):
+			return nil, "", MessageErr{CommandName: "answer", Msg: fmt.Sprintf("Poll has only %d answers", len(answers))}
+		case len(answers) <= 1:
+			return nil, "", MessageErr{CommandName: "answer", Msg: "Poll must have at least one answer"}
+		}
+
+		removed := answers[int(position)-1]
+		return slices.Delete(answers, int(position)-1, int(position)), fmt.Sprintf("Answer %s removed", removed), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return CreateSimpleDiscordResponse(summary), nil
+}
+
+// changeTemplateAnswers overwrites answers of template with answers returned by change. Summary describes change
+func changeTemplateAnswers(ctx context.Context, l *slog.Logger, db poll.Queries, guildID string, pollID int64, actorID string, change func(answers []poll.AnswerParams) ([]poll.AnswerParams, string, error)) (string, error) {
+	po, err := db.FindPoll(ctx, guildID, pollID, "")
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return "", MessageErr{error: err, CommandName: "answer", Msg: "Invalid poll ID"}
+	} else if err != nil {
+		return "", err
+	}
+
+	answers := make([]poll.AnswerParams, 0, len(po.Answers)+1)
+	for _, a := range po.Answers {
+		answers = append(answers, poll.AnswerParams{Text: a.Text, Emoji: a.Emoji})
+	}
+
+	answers, summary, err := change(answers)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = db.OverwritePoll(ctx, poll.CreatePollParams{
+		Question: po.Question,
+		GuildID:  po.GuildID,
+		AuthorID: po.AuthorID,
+		ActorID:  actorID,
+		Duration: po.Duration,
+		IsMulti:  po.IsMulti,
+		Answers:  answers,
+	})
+	if errors.Is(err, poll.ErrPollNotFound) {
+		return "", MessageErr{error: err, CommandName: "answer", Msg: "Poll was removed in meantime"}
+	} else if err != nil {
+		return "", err
+	}
+
+	l.InfoContext(ctx, "poll answers changed", "pollID", po.ID, "answers", len(answers))
+
+	summary += fmt.Sprintf(" in poll `%d`", po.ID)
+	if len(answers) > maxNativeAnswers {
+		summary += fmt.Sprintf(". Poll has more than %d answers, so post it with buttons engine", maxNativeAnswers)
+	}
+
+	return summary, nil
+}
+
+// addTemplateAnswer appends answer, which isn't answer of template yet
+func addTemplateAnswer(answers []poll.AnswerParams, answer poll.AnswerParams) ([]poll.AnswerParams, string, error) {
+	switch {
+	case len(answers) >= poll.MaxCustomAnswers:
+		return nil, "", MessageErr{CommandName: "answer", Msg: fmt.Sprintf("Poll can have up to %d answers", poll.MaxCustomAnswers)}
+	case answer.Text == "" || utf8.RuneCountInString(answer.Text) > maxAnswerLength:
+		return nil, "", MessageErr{CommandName: "answer", Msg: fmt.Sprintf("Answer must have from 1 to %d characters", maxAnswerLength)}
+	case slices.ContainsFunc(answers, func(a poll.AnswerParams) bool { return strings.EqualFold(a.Text, answer.Text) }):
+		return nil, "", MessageErr{CommandName: "answer", Msg: fmt.Sprintf("Poll already has answer %s", answer.Text)}
+	}
+
+	return append(answers, answer), fmt.Sprintf("Answer %s added", answer), nil
+}
+
+func pollAnswerCommandOption() *discordgo.ApplicationCommandOption {
+	idOption := &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionInteger,
+		Name:        "id",
+		Required:    true,
+		Description: "Poll's ID",
+	}
+
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+		Name:        pollAnswerCommandGroupName,
+		Description: "Manage answers of poll template",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        answerAddCommandName,
+				Description: "Add answer to poll template",
+				Options: []*discordgo.ApplicationCommandOption{
+					idOption,
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "text",
+						Required:    true,
+						MaxLength:   maxAnswerLength,
+						Description: "Text of answer. Placeholders are allowed",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "emoji",
+						Description: "Unicode emoji or emoji of this server shown next to answer",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        answerRemoveCommandName,
+				Description: "Remove answer from poll template",
+				Options: []*discordgo.ApplicationCommandOption{
+					idOption,
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "position",
+						Required:    true,
+						MinValue:    &answerPosition,
+						Description: "Position of answer, starting from 1",
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,74 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/wittano/yomoid/poll"
+)
+
+func customPollWithAnswers(n int) poll.CustomPoll {
+	cp := poll.CustomPoll{Question: "question", ClosesAt: time.Now().Add(time.Hour)}
+	for i := range n {
+		cp.Answers = append(cp.Answers, poll.Answer{Text: fmt.Sprintf("answer %d", i)})
+	}
+
+	return cp
+}
+
+func TestCreateCustomPollComponents(t *testing.T) {
+	now := time.Now()
+
+	buttons := createCustomPollComponents(customPollWithAnswers(12), now)
+	// 3 rows of buttons and row with voters button
+	if len(buttons) != 4 {
+		t.Fatalf("rows = %d, want 4", len(buttons))
+	}
+
+	anonymous := customPollWithAnswers(maxButtonAnswers + 1)
+	anonymous.Anonymous = true
+	menu := createCustomPollComponents(anonymous, now)
+	if len(menu) != 1 {
+		t.Fatalf("rows = %d, want 1", len(menu))
+	}
+
+	row := menu[0].(discordgo.ActionsRow)
+	if sm, ok := row.Components[0].(discordgo.SelectMenu); !ok || len(sm.Options) != maxButtonAnswers+1 || sm.MaxValues != 1 {
+		t.Fatalf("invalid select menu: %+v", row.Components[0])
+	}
+
+	closed := customPollWithAnswers(2)
+	closed.Closed = true
+	if c := createCustomPollComponents(closed, now); len(c) != 0 {
+		t.Fatalf("closed poll has %d rows", len(c))
+	}
+}
+
+func TestCustomPollResults(t *testing.T) {
+	cp := customPollWithAnswers(2)
+	p := customPollResults(cp, poll.Tally{Votes: []int{3, 1}, Total: 4}, time.Now())
+
+	msg := &discordgo.Message{Poll: p}
+	if votes := messageVotes(msg); votes != 4 {
+		t.Fatalf("votes = %d, want 4", votes)
+	}
+
+	if skip, _ := skipReminder(msg, 0, time.Now()); skip {
+		t.Fatal("reminder of open custom poll was skipped")
+	}
+}
+
+func TestCreateCustomPollEmbedWeighted(t *testing.T) {
+	cp := customPollWithAnswers(2)
+	cp.Weights = []poll.RoleWeight{{RoleID: "1", Weight: 3}}
+
+	embed := createCustomPollEmbed(cp, poll.Tally{Votes: []int{1, 2}, Weighted: []int{3, 2}, Total: 3, WeightedTotal: 5}, time.Now())
+	for _, want := range []string{"3 weighted (60%), 1 votes", "2 weighted (40%), 2 votes", "Weights: <@&1>=3"} {
+		if !strings.Contains(embed.Description, want) {
+			t.Fatalf("description %q doesn't contain %q", embed.Description, want)
+		}
+	}
+}
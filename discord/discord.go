@@ -5,9 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"github.com/bwmarrin/discordgo"
+	"github.com/wittano/yomoid/lifecycle"
 	"github.com/wittano/yomoid/logger"
+	"github.com/wittano/yomoid/metrics"
 	"github.com/wittano/yomoid/poll"
+	"github.com/wittano/yomoid/settings"
+	"github.com/wittano/yomoid/tracing"
+	"go.opentelemetry.io/otel/trace"
 	"log/slog"
+	"net/http"
 	"strings"
 	"time"
 )
@@ -16,6 +22,17 @@ type SlashCommandHandler interface {
 	HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error)
 }
 
+// DeferredHandler is implemented by handlers, which can answer later than Discord's 3 seconds limit.
+// Deferred interaction is acknowledged first and the response is sent as an edit
+type DeferredHandler interface {
+	Deferred(i *discordgo.InteractionCreate) bool
+}
+
+func isDeferred(h SlashCommandHandler, i *discordgo.InteractionCreate) bool {
+	d, ok := h.(DeferredHandler)
+	return ok && d.Deferred(i)
+}
+
 type MessageErr struct {
 	error
 	CommandName string
@@ -33,30 +50,141 @@ func (e MessageErr) Error() string {
 	return fmt.Sprintf("discord slashCommand %s: %s", e.CommandName, msg)
 }
 
-var subCommandMap map[string]SlashCommandHandler
+var (
+	subCommandMap map[string]SlashCommandHandler
+	// componentMap routes buttons and modals by prefix of custom ID
+	componentMap map[string]SlashCommandHandler
+	// autocompleteMap routes autocomplete requests by name of focused option
+	autocompleteMap        map[string]SlashCommandHandler
+	slashCommandTimeout    = 2 * time.Second
+	deferredCommandTimeout = 30 * time.Second
+	// lc is root of deferred commands, which outlive context of interaction event
+	lc *lifecycle.Manager
+)
 
-func InitSlashCommandList(db poll.Queries, handler *poll.MessageCreateHandler) {
+func InitSlashCommandList(db poll.Queries, guildSettings *settings.Service, handler *poll.MessageCreateHandler) {
 	subCommandMap = map[string]SlashCommandHandler{
-		"poll": NewPollCommand(db, handler),
+		"poll":                  NewPollCommand(db, handler),
+		"yomoid":                NewYomoidCommand(guildSettings),
+		saveTemplateCommandName: SaveTemplateCommand{Db: db, Settings: guildSettings},
+	}
+	componentMap = map[string]SlashCommandHandler{
+		templateOverwriteID:    TemplateOverwriteButton{Db: db, Settings: guildSettings},
+		templateRenameID:       TemplateRenameButton{Settings: guildSettings},
+		templateRenameModalID:  TemplateRenameModal{Db: db, Settings: guildSettings},
+		customPollVoteID:       CustomPollVoteButton{Db: db},
+		customPollSelectID:     CustomPollSelect{Db: db},
+		customPollVotersID:     CustomPollVotersButton{Db: db},
+		customPollRankID:       CustomPollRankButton{Db: db},
+		customPollRankPickID:   CustomPollRankPick{Db: db},
+		customPollRankResetID:  CustomPollRankPick{Db: db, Reset: true},
+		customPollOtherID:      CustomPollOtherButton{Db: db},
+		customPollOtherModalID: CustomPollOtherModal{Db: db},
+		customPollRepliesID:    CustomPollRepliesButton{Db: db, Settings: guildSettings},
+		customPollPromoteID:    CustomPollPromoteSelect{Db: db, Settings: guildSettings},
+		pollPreviewPostID:      PollPreviewPostButton{Db: db, Settings: guildSettings},
+		pollPreviewCancelID:    PollPreviewCancelButton{},
 	}
+	autocompleteMap = map[string]SlashCommandHandler{
+		"tag": TagAutocomplete{Db: db},
+	}
+}
+
+// CommandDefinitions returns all slash commands handled by bot
+func CommandDefinitions() []*discordgo.ApplicationCommand {
+	return []*discordgo.ApplicationCommand{
+		NewPollCommandDefinition(),
+		NewYomoidCommandDefinition(),
+		NewSaveTemplateCommandDefinition(),
+	}
+}
+
+// SetTimeouts changes timeout of handling slash command, deferred slash command and timeout of HTTP client used e.g.
+// for downloading avatars
+func SetTimeouts(command, deferredCommand, httpClient time.Duration) {
+	slashCommandTimeout = command
+	deferredCommandTimeout = deferredCommand
+	client.Timeout = httpClient
 }
 
-func HandleSlashCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	const slashCommandTimeout = time.Duration(float64(time.Second) * 2)
-	ctx, cancel := context.WithTimeout(context.Background(), slashCommandTimeout)
+// SetLifecycle sets manager, whose root context is used by deferred slash commands. It must be called before handling
+// interactions
+func SetLifecycle(m *lifecycle.Manager) {
+	lc = m
+}
+
+// HandleInteraction routes slash commands, context menu commands, message components and modals
+func HandleInteraction(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	ctx, cancel := context.WithTimeout(ctx, slashCommandTimeout)
 	defer cancel()
 
+	var (
+		commandName, subCommandName string
+		handlers                    map[string]SlashCommandHandler
+	)
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		commandName = i.ApplicationCommandData().Name
+		subCommandName = findSubCommandName(i.ApplicationCommandData())
+		handlers = subCommandMap
+	case discordgo.InteractionMessageComponent:
+		commandName = "component"
+		subCommandName, _ = parseCustomID(i.MessageComponentData().CustomID)
+		handlers = componentMap
+	case discordgo.InteractionModalSubmit:
+		commandName = "modal"
+		subCommandName, _ = parseCustomID(i.ModalSubmitData().CustomID)
+		handlers = componentMap
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		commandName = "autocomplete"
+		if opt := focusedOption(i.ApplicationCommandData()); opt != nil {
+			subCommandName = opt.Name
+		}
+		handlers = autocompleteMap
+	default:
+		return
+	}
+
+	start := time.Now()
+	defer metrics.SlashCommandDuration.ObserveSince(start, commandName, subCommandName)
+	metrics.SlashCommands.Inc(commandName, subCommandName)
+
+	ctx, span := tracing.StartEvent(ctx, "interaction "+strings.TrimSpace(commandName+" "+subCommandName), i.GuildID, i.ChannelID)
+	defer span.End()
+
 	l := logger.NewLoggerFromInteraction(ctx, s, *i.Interaction).
-		With("commandName", i.ApplicationCommandData().Name)
+		With("commandName", commandName)
 
-	handler, ok := subCommandMap[i.ApplicationCommandData().Name]
+	handlerName := commandName
+	if i.Type != discordgo.InteractionApplicationCommand {
+		handlerName = subCommandName
+	}
+
+	handler, ok := handlers[handlerName]
 	if !ok {
-		l.WarnContext(ctx, "unknown slash command")
+		l.WarnContext(ctx, "unknown interaction", "handler", handlerName)
 		return
 	}
 
 	l.InfoContext(ctx, "slash command handler received a new command")
 
+	deferred := i.Type == discordgo.InteractionApplicationCommand && isDeferred(handler, i)
+	if deferred {
+		ack := &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+		}
+		if err := s.InteractionRespond(i.Interaction, ack, discordgo.WithContext(ctx)); err != nil {
+			l.ErrorContext(ctx, "failed defer interaction response", "error", err)
+			tracing.RecordError(span, err)
+			return
+		}
+
+		var cancelDeferred context.CancelFunc
+		ctx, cancelDeferred = context.WithTimeout(trace.ContextWithSpan(lc.Context(), span), deferredCommandTimeout)
+		defer cancelDeferred()
+	}
+
 	res, err := handler.HandleSlashCommand(ctx, l, s, i)
 	if err != nil {
 		var (
@@ -66,6 +194,8 @@ func HandleSlashCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		if errors.As(err, &discordErr) {
 			content = discordErr.Msg
 		}
+		metrics.Errors.Inc(errorType(err))
+		tracing.RecordError(span, err)
 
 		l.ErrorContext(ctx, "unexpected failed handle slash command", "error", err)
 		res = &discordgo.InteractionResponse{
@@ -75,23 +205,151 @@ func HandleSlashCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		}
+
+		// Autocomplete can't be answered by message
+		if i.Type == discordgo.InteractionApplicationCommandAutocomplete {
+			res = createAutocompleteResponse(nil)
+		}
 	}
 
-	if err = s.InteractionRespond(i.Interaction, res); err != nil {
+	if deferred {
+		_, err = s.InteractionResponseEdit(i.Interaction, createWebhookEdit(res.Data), discordgo.WithContext(ctx))
+	} else {
+		err = s.InteractionRespond(i.Interaction, res, discordgo.WithContext(ctx))
+	}
+
+	if err != nil {
 		l.ErrorContext(ctx, "failed send interaction respond to slash command", "error", err)
+		tracing.RecordError(span, err)
 	} else {
 		l.Info("response for interaction")
 	}
 }
 
+// maxJobAttempts is number of failed runs of background job for single row, after which the row is given up, so it
+// doesn't block next rows
+const maxJobAttempts = 5
+
+// isRESTStatus checks if Discord API responded with status code
+func isRESTStatus(err error, code int) bool {
+	var restErr *discordgo.RESTError
+	return errors.As(err, &restErr) && restErr.Response != nil && restErr.Response.StatusCode == code
+}
+
+// isPermanentRESTErr checks if Discord API rejected request, because resource was removed or bot lost access to it.
+// The same request fails again
+func isPermanentRESTErr(err error) bool {
+	return isRESTStatus(err, http.StatusForbidden) || isRESTStatus(err, http.StatusNotFound)
+}
+
+func createWebhookEdit(data *discordgo.InteractionResponseData) *discordgo.WebhookEdit {
+	edit := &discordgo.WebhookEdit{Content: &data.Content}
+	if data.Embeds != nil {
+		edit.Embeds = &data.Embeds
+	}
+	if data.Components != nil {
+		edit.Components = &data.Components
+	}
+
+	return edit
+}
+
+// customID joins component's prefix, used to route interaction, with its arguments
+func customID(prefix string, args ...string) string {
+	return strings.Join(append([]string{prefix}, args...), ":")
+}
+
+// parseCustomID splits custom ID of component or modal created by customID
+func parseCustomID(id string) (prefix string, args []string) {
+	parts := strings.Split(id, ":")
+
+	return parts[0], parts[1:]
+}
+
+func findSubCommandName(data discordgo.ApplicationCommandInteractionData) string {
+	if len(data.Options) == 0 {
+		return ""
+	}
+
+	switch opt := data.Options[0]; opt.Type {
+	case discordgo.ApplicationCommandOptionSubCommand:
+		return opt.Name
+	case discordgo.ApplicationCommandOptionSubCommandGroup:
+		if len(opt.Options) > 0 {
+			return opt.Name + " " + opt.Options[0].Name
+		}
+		return opt.Name
+	default:
+		return ""
+	}
+}
+
+// subCommandOptions returns arguments of invoked subcommand. Subcommand can be nested in group
+func subCommandOptions(data discordgo.ApplicationCommandInteractionData) []*discordgo.ApplicationCommandInteractionDataOption {
+	if len(data.Options) == 0 {
+		return nil
+	}
+
+	opts := data.Options[0].Options
+	if data.Options[0].Type == discordgo.ApplicationCommandOptionSubCommandGroup {
+		if len(opts) == 0 {
+			return nil
+		}
+		opts = opts[0].Options
+	}
+
+	return opts
+}
+
+// focusedOption returns option, which user is typing during autocomplete
+func focusedOption(data discordgo.ApplicationCommandInteractionData) *discordgo.ApplicationCommandInteractionDataOption {
+	opts := data.Options
+	if len(opts) > 0 && (opts[0].Type == discordgo.ApplicationCommandOptionSubCommand || opts[0].Type == discordgo.ApplicationCommandOptionSubCommandGroup) {
+		opts = subCommandOptions(data)
+	}
+
+	for _, opt := range opts {
+		if opt != nil && opt.Focused {
+			return opt
+		}
+	}
+
+	return nil
+}
+
+func createAutocompleteResponse(choices []*discordgo.ApplicationCommandOptionChoice) *discordgo.InteractionResponse {
+	if choices == nil {
+		choices = []*discordgo.ApplicationCommandOptionChoice{}
+	}
+
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	}
+}
+
+func errorType(err error) string {
+	var discordErr MessageErr
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return metrics.ErrorTypeTimeout
+	case errors.As(err, &discordErr):
+		return metrics.ErrorTypeUser
+	default:
+		return metrics.ErrorTypeInternal
+	}
+}
+
 func parseInteractionInput(i discordgo.Interaction) (in map[string]any) {
 	if len(i.ApplicationCommandData().Options) == 0 {
 		return nil
 	}
 
-	in = make(map[string]any, len(i.ApplicationCommandData().Options[0].Options))
+	opts := subCommandOptions(i.ApplicationCommandData())
+	in = make(map[string]any, len(opts))
 
-	for _, data := range i.ApplicationCommandData().Options[0].Options {
+	for _, data := range opts {
 		if data == nil {
 			continue
 		}
@@ -102,6 +360,39 @@ func parseInteractionInput(i discordgo.Interaction) (in map[string]any) {
 	return
 }
 
+// interactionUserID returns ID of user, who invoked interaction in guild or DM
+func interactionUserID(i *discordgo.InteractionCreate) string {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User.ID
+	} else if i.User != nil {
+		return i.User.ID
+	}
+
+	return ""
+}
+
+// interactionUserName returns display name of user, who invoked interaction
+func interactionUserName(i *discordgo.InteractionCreate) string {
+	if i.Member != nil && i.Member.Nick != "" {
+		return i.Member.Nick
+	}
+
+	var user *discordgo.User
+	if i.Member != nil && i.Member.User != nil {
+		user = i.Member.User
+	} else {
+		user = i.User
+	}
+
+	if user == nil {
+		return ""
+	} else if user.GlobalName != "" {
+		return user.GlobalName
+	}
+
+	return user.Username
+}
+
 func CreateSimpleDiscordResponse(msg string) *discordgo.InteractionResponse {
 	return &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -0,0 +1,49 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestCustomID(t *testing.T) {
+	id := customID(templateOverwriteID, "123", "456")
+	if id != "template-overwrite:123:456" {
+		t.Fatalf("invalid custom ID %q", id)
+	}
+
+	prefix, args := parseCustomID(id)
+	if prefix != templateOverwriteID || !slices.Equal(args, []string{"123", "456"}) {
+		t.Fatalf("invalid parsed custom ID. prefix: %q, args: %v", prefix, args)
+	}
+
+	if prefix, args = parseCustomID(templateRenameID); prefix != templateRenameID || len(args) != 0 {
+		t.Fatalf("invalid parsed custom ID without arguments. prefix: %q, args: %v", prefix, args)
+	}
+}
+
+func TestIsPermanentRESTErr(t *testing.T) {
+	restErr := func(code int) error {
+		return fmt.Errorf("request: %w", &discordgo.RESTError{Response: &http.Response{StatusCode: code}})
+	}
+
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{restErr(http.StatusForbidden), true},
+		{restErr(http.StatusNotFound), true},
+		{restErr(http.StatusInternalServerError), false},
+		{context.DeadlineExceeded, false},
+	}
+
+	for _, tt := range tests {
+		if got := isPermanentRESTErr(tt.err); got != tt.want {
+			t.Fatalf("isPermanentRESTErr(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/wittano/yomoid/poll"
+)
+
+var errUnknownEmoji = errors.New("unknown emoji")
+
+// checkEmojis returns errUnknownEmoji, when custom emoji of answer doesn't exist in guild. Discord rejects poll and
+// components with unknown emoji
+func checkEmojis(ctx context.Context, s *discordgo.Session, guildID string, answers []poll.Answer) error {
+	if !slices.ContainsFunc(answers, func(a poll.Answer) bool { return a.Emoji.IsCustom() }) {
+		return nil
+	}
+
+	emojis, err := s.GuildEmojis(guildID, discordgo.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	for i, a := range answers {
+		if a.Emoji.IsCustom() && !slices.ContainsFunc(emojis, func(e *discordgo.Emoji) bool { return e.ID == a.Emoji.ID }) {
+			return fmt.Errorf("%w :%s: in answer %d. It was removed from server", errUnknownEmoji, a.Emoji.Name, i+1)
+		}
+	}
+
+	return nil
+}
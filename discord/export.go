@@ -0,0 +1,162 @@
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/wittano/yomoid/poll"
+	"github.com/wittano/yomoid/settings"
+)
+
+const (
+	pollExportCommandName     = "export"
+	pollImportFileCommandName = "import-file"
+
+	exportFileName = "polls.json"
+	// maxImportFileSize is limit of downloaded file with templates
+	maxImportFileSize = 1 << 20
+)
+
+// PollExportCommand sends templates of guild with tags as JSON file
+type PollExportCommand struct {
+	Db poll.Queries
+}
+
+func (c PollExportCommand) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	tag, _ := parseInteractionInput(*i.Interaction)["tag"].(string)
+	if tag != "" {
+		normalized, err := poll.NormalizeTag(tag)
+		if err != nil {
+			return nil, MessageErr{error: err, CommandName: "export", Msg: fmt.Sprintf("Invalid tag %q: %s", tag, err)}
+		}
+		tag = normalized
+	}
+
+	export, err := c.Db.ExportPolls(ctx, i.GuildID, tag)
+	if err != nil {
+		return nil, err
+	} else if len(export.Polls) == 0 {
+		return CreateSimpleDiscordResponse("There are no templates to export"), nil
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	l.InfoContext(ctx, "poll templates exported", "polls", len(export.Polls), "tag", tag)
+
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("Exported %d templates. Import them by /poll %s", len(export.Polls), pollImportFileCommandName),
+			Files: []*discordgo.File{
+				{Name: exportFileName, ContentType: "application/json", Reader: bytes.NewReader(data)},
+			},
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	}, nil
+}
+
+// PollImportFileCommand saves templates with tags from file created by PollExportCommand
+type PollImportFileCommand struct {
+	Db       poll.Queries
+	Settings *settings.Service
+}
+
+// Deferred is true, because file is downloaded and all templates are saved before answer
+func (PollImportFileCommand) Deferred(*discordgo.InteractionCreate) bool {
+	return true
+}
+
+func (c PollImportFileCommand) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	guildSettings, err := c.Settings.Get(ctx, i.GuildID)
+	if err != nil {
+		return nil, err
+	} else if !isGuildAdmin(i.Member, guildSettings) {
+		return nil, MessageErr{CommandName: "import", Msg: "You don't have permission to import poll templates"}
+	}
+
+	id, _ := parseInteractionInput(*i.Interaction)["file"].(string)
+	attachment, ok := i.ApplicationCommandData().Resolved.Attachments[id]
+	if !ok {
+		return nil, fmt.Errorf("import: missing attachment %q", id)
+	} else if attachment.Size > maxImportFileSize {
+		return nil, MessageErr{CommandName: "import", Msg: fmt.Sprintf("File can't be bigger than %d KiB", maxImportFileSize>>10)}
+	}
+
+	polls, err := downloadExport(ctx, attachment.URL)
+	if errors.Is(err, poll.ErrInvalidImport) {
+		return nil, MessageErr{error: err, CommandName: "import", Msg: "Invalid file: " + strings.TrimPrefix(err.Error(), poll.ErrInvalidImport.Error()+": ")}
+	} else if err != nil {
+		return nil, err
+	}
+
+	ids, err := c.Db.ImportPolls(ctx, i.GuildID, interactionUserID(i), polls)
+	var importErr poll.ImportError
+	if errors.Is(err, poll.ErrPollExists) && errors.As(err, &importErr) {
+		return nil, MessageErr{
+			error:       err,
+			CommandName: "import",
+			Msg:         fmt.Sprintf("Template with question %s already exists. Nothing was imported", importErr.Question),
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	l.InfoContext(ctx, "poll templates imported", "pollIDs", ids)
+
+	return CreateSimpleDiscordResponse(fmt.Sprintf("Imported %d templates", len(ids))), nil
+}
+
+// downloadExport fetches and parses file with exported templates
+func downloadExport(ctx context.Context, url string) ([]poll.ExportedPoll, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("import: download file: unexpected status %s", res.Status)
+	}
+
+	return poll.ParseExport(io.LimitReader(res.Body, maxImportFileSize))
+}
+
+func pollExportCommandOptions() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        pollExportCommandName,
+			Description: "Export templates with tags as JSON file",
+			Options:     []*discordgo.ApplicationCommandOption{tagOption(false)},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        pollImportFileCommandName,
+			Description: "Import templates with tags from file created by /poll export",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionAttachment,
+					Name:        "file",
+					Required:    true,
+					Description: "JSON file with templates",
+				},
+			},
+		},
+	}
+}
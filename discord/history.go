@@ -0,0 +1,145 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/wittano/yomoid/poll"
+	"github.com/wittano/yomoid/settings"
+)
+
+// maxHistoryRevisions is limit of embed fields in single message
+const maxHistoryRevisions = 25
+
+var minRevision float64 = 1
+
+type PollHistoryCommand struct {
+	Db poll.Queries
+}
+
+func (c PollHistoryCommand) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	id, ok := parseInteractionInput(*i.Interaction)["id"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("poll: missing id argument")
+	}
+
+	revisions, err := c.Db.FindRevisions(ctx, i.GuildID, int64(id))
+	if errors.Is(err, poll.ErrPollNotFound) {
+		return nil, MessageErr{error: err, CommandName: "history", Msg: "Invalid poll ID"}
+	} else if err != nil {
+		return nil, err
+	}
+
+	return createHistoryResponse(revisions), nil
+}
+
+// createHistoryResponse shows the newest revisions with changes compared to previous revision
+func createHistoryResponse(revisions []poll.Revision) *discordgo.InteractionResponse {
+	latest := revisions[len(revisions)-1]
+	start := max(0, len(revisions)-maxHistoryRevisions)
+
+	fields := make([]*discordgo.MessageEmbedField, 0, len(revisions)-start)
+	for j := len(revisions) - 1; j >= start; j-- {
+		var (
+			rev     = revisions[j]
+			changes []string
+		)
+		if j == 0 {
+			changes = poll.Diff(poll.Revision{}, rev)
+		} else {
+			changes = poll.Diff(revisions[j-1], rev)
+		}
+
+		value := "no changes"
+		if len(changes) > 0 {
+			value = "```diff\n" + strings.Join(changes, "\n") + "\n```"
+		}
+		value = fmt.Sprintf("by <@%s> <t:%d:R>\n%s", rev.AuthorID, rev.CreatedAt.Unix(), value)
+
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:  fmt.Sprintf("Revision %d", rev.Revision),
+			Value: truncateCodeBlock(value, 1024),
+		})
+	}
+
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{
+				{
+					Title:  fmt.Sprintf("History of Model **#%d**", latest.PollID),
+					Fields: fields,
+					Footer: &discordgo.MessageEmbedFooter{
+						Text: fmt.Sprintf("Current revision: %d", latest.Revision),
+					},
+				},
+			},
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	}
+}
+
+// truncateCodeBlock cuts too long text ended by code block and closes the block
+func truncateCodeBlock(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+
+	const suffix = "\n…\n```"
+	end := limit - len(suffix)
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+
+	return text[:end] + suffix
+}
+
+type PollRollbackCommand struct {
+	Db       poll.Queries
+	Settings *settings.Service
+}
+
+func (c PollRollbackCommand) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	guildSettings, err := c.Settings.Get(ctx, i.GuildID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isGuildAdmin(i.Member, guildSettings) {
+		return nil, MessageErr{CommandName: "rollback", Msg: "You don't have permission to roll back polls"}
+	}
+
+	args := parseInteractionInput(*i.Interaction)
+
+	id, ok := args["id"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("poll: missing id argument")
+	}
+
+	revision, ok := args["revision"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("poll: missing revision argument")
+	}
+
+	rev, err := c.Db.RollbackPoll(ctx, i.GuildID, int64(id), int32(revision), interactionUserID(i))
+	if errors.Is(err, poll.ErrRevisionNotFound) {
+		return nil, MessageErr{error: err, CommandName: "rollback", Msg: fmt.Sprintf("Revision %d of poll #%d not found", int(revision), int(id))}
+	} else if errors.Is(err, poll.ErrPollExists) {
+		return nil, MessageErr{error: err, CommandName: "rollback", Msg: fmt.Sprintf("Another poll with question %s already exists", rev.Question)}
+	} else if err != nil {
+		return nil, err
+	}
+
+	l.InfoContext(ctx, "poll rolled back", "pollID", rev.PollID, "fromRevision", int(revision), "revision", rev.Revision)
+
+	return CreateSimpleDiscordResponse(fmt.Sprintf(
+		"Model #%d rolled back to revision %d and saved as revision %d at %s",
+		rev.PollID, int(revision), rev.Revision, rev.CreatedAt.Format(time.RFC822),
+	)), nil
+}
@@ -11,20 +11,32 @@ import (
 	"regexp"
 	"sync"
 	"time"
+
+	"github.com/wittano/yomoid/metrics"
+	"github.com/wittano/yomoid/tracing"
 )
 
 var ErrMainColorNotFound = errors.New("poll: failed detect main color")
 
 var client = http.Client{
-	Timeout: time.Second * 5,
+	Timeout:   time.Second * 5,
+	Transport: tracing.Transport{},
 }
 
 var onlyPngUrlRegex = regexp.MustCompile(`\\.[a-z]{3,4}\\?`)
 
-func downloadImage(url string) (io.ReadCloser, error) {
+func downloadImage(ctx context.Context, url string) (_ io.ReadCloser, err error) {
+	ctx, span := tracing.Start(ctx, "downloadImage")
+	defer func() { tracing.End(span, err) }()
+
 	url = onlyPngUrlRegex.ReplaceAllString(url, ".png?")
 
-	res, err := client.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -32,7 +44,12 @@ func downloadImage(url string) (io.ReadCloser, error) {
 	return res.Body, nil
 }
 
-func imageMainColor(ctx context.Context, reader io.Reader) (uint32, error) {
+func imageMainColor(ctx context.Context, reader io.Reader) (_ uint32, err error) {
+	defer metrics.ImageMainColorDuration.ObserveSince(time.Now())
+
+	ctx, span := tracing.Start(ctx, "imageMainColor")
+	defer func() { tracing.End(span, err) }()
+
 	img, _, err := image.Decode(reader)
 	if err != nil {
 		return 0, err
@@ -9,7 +9,7 @@ import (
 )
 
 func TestDownloadImageAndSearchMainColor(t *testing.T) {
-	img, err := downloadImage("https://cdn.discordapp.com/avatars/299551004628615178/338422b3211015675aa87d130bba6aec.png?size=128")
+	img, err := downloadImage(context.Background(), "https://cdn.discordapp.com/avatars/299551004628615178/338422b3211015675aa87d130bba6aec.png?size=128")
 	if err != nil {
 		t.Fatal(err)
 	}
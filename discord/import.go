@@ -0,0 +1,163 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/wittano/yomoid/poll"
+	"github.com/wittano/yomoid/settings"
+)
+
+var errInvalidMessageLink = errors.New("discord: invalid message link")
+
+// parseMessageLink returns IDs from message link e.g. https://discord.com/channels/<guild>/<channel>/<message>
+func parseMessageLink(link string) (guildID, channelID, messageID string, err error) {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return "", "", "", errors.Join(errInvalidMessageLink, err)
+	}
+
+	switch strings.ToLower(u.Hostname()) {
+	case "discord.com", "ptb.discord.com", "canary.discord.com", "discordapp.com":
+	default:
+		return "", "", "", errInvalidMessageLink
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) != 4 || parts[0] != "channels" {
+		return "", "", "", errInvalidMessageLink
+	}
+
+	for _, id := range parts[1:] {
+		if !isSnowflake(id) {
+			return "", "", "", errInvalidMessageLink
+		}
+	}
+
+	return parts[1], parts[2], parts[3], nil
+}
+
+func isSnowflake(id string) bool {
+	if id == "" {
+		return false
+	}
+
+	for _, c := range id {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+type PollImportCommand struct {
+	Db       poll.Queries
+	Settings *settings.Service
+}
+
+func (p PollImportCommand) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	guildSettings, err := p.Settings.Get(ctx, i.GuildID)
+	if err != nil {
+		return nil, err
+	} else if !isGuildAdmin(i.Member, guildSettings) {
+		return nil, MessageErr{CommandName: "import", Msg: "You don't have permission to import poll templates"}
+	}
+
+	link, _ := parseInteractionInput(*i.Interaction)["link"].(string)
+
+	guildID, channelID, messageID, err := parseMessageLink(link)
+	if err != nil {
+		return nil, MessageErr{
+			error:       err,
+			CommandName: "import",
+			Msg:         "Invalid message link. Use `Copy Message Link` option on message with poll",
+		}
+	}
+
+	l.InfoContext(ctx, "poll import request received", "sourceGuildID", guildID, "sourceChannelID", channelID, "sourceMessageID", messageID)
+
+	if err = checkSourceAccess(ctx, s, interactionUserID(i), guildID, channelID); err != nil {
+		return nil, err
+	}
+
+	msg, err := s.ChannelMessage(channelID, messageID, discordgo.WithContext(ctx))
+	if err != nil {
+		return nil, messageAccessErr(err)
+	}
+
+	if msg.Poll == nil || msg.Poll.Question.Text == "" || len(msg.Poll.Answers) == 0 {
+		return nil, MessageErr{CommandName: "import", Msg: "Linked message doesn't contain poll"}
+	}
+
+	params := poll.NewCreatePollParams(*msg, guildSettings.DefaultDuration)
+	params.GuildID = i.GuildID
+	// Template belongs to importing user, not to author of message from other channel or guild
+	params.AuthorID = interactionUserID(i)
+	params.ActorID = params.AuthorID
+
+	pollID, err := p.Db.CreatePoll(ctx, params)
+	if errors.Is(err, poll.ErrPollExists) {
+		return nil, MessageErr{
+			error:       err,
+			CommandName: "import",
+			Msg:         fmt.Sprintf("Template with question %s already exists", params.Question),
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	l.InfoContext(ctx, "poll imported", "pollID", pollID)
+
+	return CreateSimpleDiscordResponse(fmt.Sprintf("Poll %s imported as template `%d`", params.Question, pollID)), nil
+}
+
+// checkSourceAccess rejects channel, which user can't read. Bot can read channels of other guilds, which user can't
+// see
+func checkSourceAccess(ctx context.Context, s *discordgo.Session, userID, guildID, channelID string) error {
+	channel, err := findChannel(ctx, s, channelID)
+	if err != nil {
+		return err
+	} else if channel.GuildID != guildID {
+		return MessageErr{CommandName: "import", Msg: "Linked message or channel doesn't exist"}
+	}
+
+	// User, who isn't member of linked guild, isn't found and doesn't have any permission there
+	permissions, err := s.UserChannelPermissions(userID, channelID, discordgo.WithContext(ctx))
+	if err != nil && !isRESTStatus(err, http.StatusNotFound) {
+		return messageAccessErr(err)
+	}
+
+	const required = discordgo.PermissionViewChannel | discordgo.PermissionReadMessageHistory
+	if permissions&required != required {
+		return MessageErr{CommandName: "import", Msg: "You can import only polls from channels, which you can read"}
+	}
+
+	return nil
+}
+
+// messageAccessErr maps errors returned by Discord REST API into message for user
+func messageAccessErr(err error) error {
+	var restErr *discordgo.RESTError
+	if !errors.As(err, &restErr) || restErr.Response == nil {
+		return err
+	}
+
+	msg := "I can't fetch linked message. Try again later"
+	switch restErr.Response.StatusCode {
+	case http.StatusForbidden:
+		msg = "I don't have access to linked channel. Make sure I'm member of this server and I can read message history"
+	case http.StatusNotFound:
+		msg = "Linked message or channel doesn't exist"
+	case http.StatusUnauthorized:
+		return err
+	}
+
+	return MessageErr{error: err, CommandName: "import", Msg: msg}
+}
@@ -0,0 +1,44 @@
+package discord
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseMessageLink(t *testing.T) {
+	tests := []struct {
+		link                          string
+		guildID, channelID, messageID string
+		valid                         bool
+	}{
+		{"https://discord.com/channels/1/22/333", "1", "22", "333", true},
+		{"https://ptb.discord.com/channels/1/22/333", "1", "22", "333", true},
+		{"https://canary.discord.com/channels/1/22/333/", "1", "22", "333", true},
+		{"https://discordapp.com/channels/1/22/333", "1", "22", "333", true},
+		{"  https://discord.com/channels/1/22/333  ", "1", "22", "333", true},
+		{"https://discord.com/channels/@me/22/333", "", "", "", false},
+		{"https://discord.com/channels/1/22", "", "", "", false},
+		{"https://example.com/channels/1/22/333", "", "", "", false},
+		{"https://discord.com/invite/1/22/333", "", "", "", false},
+		{"not a link", "", "", "", false},
+	}
+
+	for _, test := range tests {
+		guildID, channelID, messageID, err := parseMessageLink(test.link)
+		if !test.valid {
+			if !errors.Is(err, errInvalidMessageLink) {
+				t.Errorf("%q: expected invalid link error, got: %v", test.link, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%q: %v", test.link, err)
+			continue
+		}
+
+		if guildID != test.guildID || channelID != test.channelID || messageID != test.messageID {
+			t.Errorf("%q: invalid IDs %s/%s/%s", test.link, guildID, channelID, messageID)
+		}
+	}
+}
@@ -0,0 +1,442 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/wittano/yomoid/poll"
+)
+
+const pollResultsCommandName = "results"
+
+const (
+	// maxPostTargets limits channels, in which single /poll create posts poll. It's the same for channel options and
+	// channels of category
+	maxPostTargets = 5
+	// postConcurrency limits parallel requests to Discord. discordgo waits for rate limit buckets itself, so
+	// small number of workers doesn't exhaust global limit
+	postConcurrency = 3
+)
+
+// extraChannelOptions are additional channels of /poll create
+var extraChannelOptions = []string{"channel-2", "channel-3", "channel-4", "channel-5"}
+
+// isMultiTarget checks if /poll create should post poll in more than one channel
+func isMultiTarget(args map[string]any) bool {
+	if _, ok := args["category"]; ok {
+		return true
+	}
+
+	for _, name := range extraChannelOptions {
+		if _, ok := args[name]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findPostTargets collects unique text channels passed directly or as category
+func findPostTargets(ctx context.Context, s *discordgo.Session, guildID string, args map[string]any) ([]*discordgo.Channel, error) {
+	var ids []string
+	for _, name := range append([]string{"channel"}, extraChannelOptions...) {
+		if id, ok := args[name].(string); ok && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	targets := make([]*discordgo.Channel, 0, len(ids))
+	for _, id := range ids {
+		c, err := findChannel(ctx, s, id)
+		if err != nil {
+			return nil, err
+		}
+
+		targets = append(targets, c)
+	}
+
+	if categoryID, ok := args["category"].(string); ok {
+		channels, err := findCategoryChannels(ctx, s, guildID, categoryID)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(channels) == 0 {
+			return nil, MessageErr{CommandName: "post", Msg: fmt.Sprintf("Category <#%s> doesn't have text channels", categoryID)}
+		}
+
+		for _, c := range channels {
+			if !slices.ContainsFunc(targets, func(t *discordgo.Channel) bool { return t.ID == c.ID }) {
+				targets = append(targets, c)
+			}
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil, MessageErr{CommandName: "post", Msg: "Pass channel or category, where poll will be posted"}
+	} else if len(targets) > maxPostTargets {
+		return nil, MessageErr{CommandName: "post", Msg: fmt.Sprintf("Poll can be posted in up to %d channels at once, but %d channels were selected", maxPostTargets, len(targets))}
+	}
+
+	return targets, nil
+}
+
+// findCategoryChannels returns text channels of category sorted by position
+func findCategoryChannels(ctx context.Context, s *discordgo.Session, guildID, categoryID string) ([]*discordgo.Channel, error) {
+	var channels []*discordgo.Channel
+	if g, err := s.State.Guild(guildID); err == nil {
+		channels = g.Channels
+	} else if channels, err = s.GuildChannels(guildID, discordgo.WithContext(ctx)); err != nil {
+		return nil, err
+	}
+
+	var result []*discordgo.Channel
+	for _, c := range channels {
+		if c.ParentID == categoryID && c.Type == discordgo.ChannelTypeGuildText {
+			result = append(result, c)
+		}
+	}
+
+	slices.SortFunc(result, func(a, b *discordgo.Channel) int {
+		return a.Position - b.Position
+	})
+
+	return result, nil
+}
+
+// forEachLimited calls fn for every index from 0 to n with up to limit calls at the same time
+func forEachLimited(n, limit int, fn func(i int)) {
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, limit)
+	)
+
+	for i := range n {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			fn(i)
+		}()
+	}
+
+	wg.Wait()
+}
+
+// postResult is outcome of posting poll in single channel
+type postResult struct {
+	Channel *discordgo.Channel
+	Message *discordgo.Message
+	Err     error
+}
+
+// postPollToTargets posts the same template in every channel. Copies share batch ID, so results can be combined later
+func postPollToTargets(ctx context.Context, l *slog.Logger, s *discordgo.Session, db poll.Queries, req postRequest, targets []*discordgo.Channel) []postResult {
+	results := make([]postResult, len(targets))
+
+	// Copies are recorded concurrently, so {{count}} is resolved once for whole batch
+	count, err := db.CountPosts(ctx, req.PollID)
+	if err != nil {
+		for i, c := range targets {
+			results[i] = postResult{Channel: c, Err: err}
+		}
+
+		return results
+	}
+	req.Count = &count
+
+	forEachLimited(len(targets), postConcurrency, func(i int) {
+		r := req
+		r.Channel = targets[i]
+
+		msg, err := postPoll(ctx, l.With("channelID", targets[i].ID), s, db, r)
+		if err != nil {
+			l.WarnContext(ctx, "failed post poll copy", "targetChannelID", targets[i].ID, "error", err)
+		}
+
+		results[i] = postResult{Channel: targets[i], Message: msg, Err: err}
+	})
+
+	return results
+}
+
+func createPostSummary(pollID int64, results []postResult) string {
+	var (
+		posted int
+		b      strings.Builder
+	)
+
+	for _, r := range results {
+		if r.Err == nil {
+			posted++
+			fmt.Fprintf(&b, "\n✅ <#%s>%s", r.Channel.ID, threadMention(r.Message))
+		} else {
+			fmt.Fprintf(&b, "\n❌ <#%s>: %s", r.Channel.ID, postErrorReason(r.Err))
+		}
+	}
+
+	return fmt.Sprintf("Model #%d was posted in %d of %d channels", pollID, posted, len(results)) + b.String()
+}
+
+// postErrorReason describes, why poll wasn't posted in channel
+func postErrorReason(err error) string {
+	var (
+		msgErr       MessageErr
+		rateLimitErr *discordgo.RateLimitError
+	)
+
+	switch {
+	case errors.As(err, &msgErr):
+		return msgErr.Msg
+	case errors.As(err, &rateLimitErr):
+		return "Discord rate limit was hit. Try again later"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "Discord didn't respond in time"
+	case isRESTStatus(err, http.StatusForbidden):
+		return "I don't have permission to send polls"
+	default:
+		return "unexpected error"
+	}
+}
+
+// PollResultsCommand shows results of the latest post of poll combined across all copies posted at once
+type PollResultsCommand struct {
+	Db poll.Queries
+}
+
+func (c PollResultsCommand) Deferred(*discordgo.InteractionCreate) bool {
+	return true
+}
+
+func (c PollResultsCommand) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	id, _ := parseInteractionInput(*i.Interaction)["id"].(float64)
+
+	posts, err := c.Db.FindLatestPosts(ctx, i.GuildID, int64(id))
+	if err != nil {
+		return nil, err
+	} else if len(posts) == 0 {
+		return nil, MessageErr{CommandName: "results", Msg: fmt.Sprintf("Model #%d wasn't posted yet", int64(id))}
+	}
+
+	messages := make([]*discordgo.Message, len(posts))
+	forEachLimited(len(posts), postConcurrency, func(i int) {
+		msg, err := fetchPollMessage(ctx, s, c.Db, posts[i].ChannelID, posts[i].MessageID)
+		if err != nil {
+			l.WarnContext(ctx, "failed fetch posted poll", "postChannelID", posts[i].ChannelID, "postMessageID", posts[i].MessageID, "error", err)
+			return
+		}
+
+		messages[i] = msg
+	})
+
+	results := combineResults(messages)
+	if results.Copies == 0 {
+		return nil, MessageErr{CommandName: "results", Msg: "Posted polls were removed or I can't read them anymore"}
+	}
+
+	l.InfoContext(ctx, "poll results combined", "pollID", int64(id), "copies", results.Copies)
+
+	custom, err := findCustomResults(ctx, c.Db, posts)
+	if err != nil {
+		return nil, err
+	}
+
+	embed := createResultsEmbed(i.GuildID, posts, messages, results)
+	if custom != "" {
+		embed.Description += "\n" + custom
+	}
+
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	}, nil
+}
+
+type answerResult struct {
+	ID    int
+	Text  string
+	Votes int
+}
+
+// pollResults are votes summed across copies of the same poll
+type pollResults struct {
+	Question string
+	Answers  []answerResult
+	Total    int
+	// Copies is number of available messages with poll
+	Copies int
+	// Final is true, when Discord finished counting votes of all copies
+	Final bool
+}
+
+// combineResults sums votes by answer ID. Removed messages are passed as nil
+func combineResults(messages []*discordgo.Message) (r pollResults) {
+	r.Final = true
+
+	for _, msg := range messages {
+		if msg == nil || msg.Poll == nil {
+			continue
+		}
+
+		if r.Copies == 0 {
+			r.Question = msg.Poll.Question.Text
+			for _, a := range msg.Poll.Answers {
+				r.Answers = append(r.Answers, answerResult{ID: a.AnswerID, Text: answerLabel(a)})
+			}
+		}
+		r.Copies++
+
+		if msg.Poll.Results == nil {
+			r.Final = false
+			continue
+		}
+		r.Final = r.Final && msg.Poll.Results.Finalized
+
+		for _, count := range msg.Poll.Results.AnswerCounts {
+			if count == nil {
+				continue
+			}
+
+			idx := slices.IndexFunc(r.Answers, func(a answerResult) bool { return a.ID == count.ID })
+			if idx < 0 {
+				r.Answers = append(r.Answers, answerResult{ID: count.ID, Text: fmt.Sprintf("Answer %d", count.ID)})
+				idx = len(r.Answers) - 1
+			}
+
+			r.Answers[idx].Votes += count.Count
+			r.Total += count.Count
+		}
+	}
+
+	if r.Copies == 0 {
+		r.Final = false
+	}
+
+	return
+}
+
+func answerLabel(a discordgo.PollAnswer) string {
+	if a.Media == nil {
+		return fmt.Sprintf("Answer %d", a.AnswerID)
+	}
+
+	return poll.Answer{Text: a.Media.Text, Emoji: poll.NewEmoji(a.Media.Emoji)}.String()
+}
+
+func messageVotes(msg *discordgo.Message) (votes int) {
+	if msg == nil || msg.Poll == nil || msg.Poll.Results == nil {
+		return 0
+	}
+
+	for _, count := range msg.Poll.Results.AnswerCounts {
+		if count != nil {
+			votes += count.Count
+		}
+	}
+
+	return
+}
+
+func createResultsEmbed(guildID string, posts []poll.Post, messages []*discordgo.Message, r pollResults) *discordgo.MessageEmbed {
+	fields := make([]*discordgo.MessageEmbedField, len(r.Answers))
+	for i, a := range r.Answers {
+		var percent float64
+		if r.Total > 0 {
+			percent = float64(a.Votes) * 100 / float64(r.Total)
+		}
+
+		fields[i] = &discordgo.MessageEmbedField{
+			Name:  a.Text,
+			Value: fmt.Sprintf("%d votes (%.1f%%)", a.Votes, percent),
+		}
+	}
+
+	var copies strings.Builder
+	for i, p := range posts {
+		if messages[i] == nil {
+			fmt.Fprintf(&copies, "<#%s>: message isn't available\n", p.ChannelID)
+			continue
+		}
+
+		link := fmt.Sprintf("https://discord.com/channels/%s/%s/%s", guildID, p.ChannelID, p.MessageID)
+		fmt.Fprintf(&copies, "<#%s>: [%d votes](%s)", p.ChannelID, messageVotes(messages[i]), link)
+		if p.ThreadID != "" {
+			fmt.Fprintf(&copies, ", discussion in <#%s>", p.ThreadID)
+		}
+		copies.WriteString(quorumStatus(p))
+		copies.WriteString("\n")
+	}
+
+	status := "Voting is still open"
+	if r.Final {
+		status = "Final results"
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       r.Question,
+		Description: copies.String(),
+		Fields:      fields,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("%s. %d votes combined from %d of %d copies", status, r.Total, r.Copies, len(posts)),
+		},
+	}
+}
+
+func pollPostOptions() []*discordgo.ApplicationCommandOption {
+	opts := []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "id",
+			Required:    true,
+			Description: "Model's ID",
+		},
+		{
+			Type:         discordgo.ApplicationCommandOptionChannel,
+			Name:         "channel",
+			ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+			Description:  "Text channel where post will be posted",
+		},
+		{
+			Type:         discordgo.ApplicationCommandOptionChannel,
+			Name:         "category",
+			ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildCategory},
+			Description:  "Post poll in every text channel of category with up to 5 channels",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "thread",
+			Description: "Start discussion thread from poll. Default is set in server settings",
+		},
+	}
+
+	for _, name := range extraChannelOptions {
+		opts = append(opts, &discordgo.ApplicationCommandOption{
+			Type:         discordgo.ApplicationCommandOptionChannel,
+			Name:         name,
+			ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+			Description:  "Another text channel where post will be posted",
+		})
+	}
+
+	opts = append(opts, reminderOptions()...)
+
+	opts = append(opts, quorumOptions()...)
+	opts = append(opts, engineOptions()...)
+
+	return append(opts, weightOptions()...)
+}
@@ -0,0 +1,48 @@
+package discord
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestCombineResults(t *testing.T) {
+	newMessage := func(finalized bool, yes, no int) *discordgo.Message {
+		return &discordgo.Message{Poll: &discordgo.Poll{
+			Question: discordgo.PollMedia{Text: "Pizza?"},
+			Answers: []discordgo.PollAnswer{
+				{AnswerID: 1, Media: &discordgo.PollMedia{Text: "yes", Emoji: &discordgo.ComponentEmoji{Name: "🍕"}}},
+				{AnswerID: 2, Media: &discordgo.PollMedia{Text: "no"}},
+			},
+			Results: &discordgo.PollResults{
+				Finalized: finalized,
+				AnswerCounts: []*discordgo.PollAnswerCount{
+					{ID: 1, Count: yes},
+					{ID: 2, Count: no},
+				},
+			},
+		}}
+	}
+
+	r := combineResults([]*discordgo.Message{newMessage(true, 3, 1), nil, newMessage(false, 2, 4)})
+
+	if r.Question != "Pizza?" || r.Copies != 2 || r.Total != 10 || r.Final {
+		t.Fatalf("invalid results %+v", r)
+	}
+
+	if a := r.Answers[0]; a.Text != "🍕 yes" || a.Votes != 5 {
+		t.Fatalf("invalid first answer %+v", a)
+	}
+
+	if a := r.Answers[1]; a.Text != "no" || a.Votes != 5 {
+		t.Fatalf("invalid second answer %+v", a)
+	}
+
+	if r = combineResults([]*discordgo.Message{newMessage(true, 1, 0), newMessage(true, 0, 1)}); !r.Final {
+		t.Fatal("results of closed polls should be final")
+	}
+
+	if r = combineResults([]*discordgo.Message{nil}); r.Copies != 0 || r.Final {
+		t.Fatalf("invalid results of removed messages %+v", r)
+	}
+}
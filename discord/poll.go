@@ -5,19 +5,32 @@ import (
 	"errors"
 	"fmt"
 	"github.com/bwmarrin/discordgo"
+	"github.com/wittano/yomoid/logger"
 	"github.com/wittano/yomoid/poll"
 	"log/slog"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 type Command map[string]SlashCommandHandler
 
 const (
-	pollDetailsCommandName = "details"
-	pollListCommandName    = "list"
-	pollRemoveCommandName  = "remove"
-	pollPostCommandName    = "create"
+	pollDetailsCommandName       = "details"
+	pollListCommandName          = "list"
+	pollRemoveCommandName        = "remove"
+	pollPostCommandName          = "create"
+	pollImportCommandName        = "import"
+	pollRestoreCommandName       = "restore"
+	pollHistoryCommandName       = "history"
+	pollRollbackCommandName      = "rollback"
+	pollReminderCommandGroupName = "reminder"
+	pollTagCommandGroupName      = "tag"
+)
+
+const (
+	searchModeTitle    = "title"
+	searchModeFullText = "search"
 )
 
 func (p Command) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
@@ -29,16 +42,47 @@ func (p Command) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *disc
 	return handler.HandleSlashCommand(ctx, l, s, i)
 }
 
+func (p Command) Deferred(i *discordgo.InteractionCreate) bool {
+	handler, ok := p[i.ApplicationCommandData().Options[0].Name]
+	return ok && isDeferred(handler, i)
+}
+
 func NewPollCommand(db poll.Queries, handler *poll.MessageCreateHandler) Command {
 	if handler == nil {
 		panic("poll: missing poll message create handler")
 	}
 
 	return map[string]SlashCommandHandler{
-		pollDetailsCommandName: PollDetailsCommand{Db: db},
-		pollListCommandName:    PollListCommand{Db: db},
-		pollRemoveCommandName:  PollRemoveCommand{Db: db},
-		pollPostCommandName:    PollPostCommand{Db: db, PollMessageHandler: handler},
+		pollDetailsCommandName:    PollDetailsCommand{Db: db},
+		pollListCommandName:       PollListCommand{Db: db},
+		pollRemoveCommandName:     PollRemoveCommand{Db: db},
+		pollPostCommandName:       PollPostCommand{Db: db, PollMessageHandler: handler},
+		pollImportCommandName:     PollImportCommand{Db: db, Settings: handler.Settings},
+		pollExportCommandName:     PollExportCommand{Db: db},
+		pollImportFileCommandName: PollImportFileCommand{Db: db, Settings: handler.Settings},
+		pollRestoreCommandName:    PollRestoreCommand{Db: db},
+		pollHistoryCommandName:    PollHistoryCommand{Db: db},
+		pollRollbackCommandName:   PollRollbackCommand{Db: db, Settings: handler.Settings},
+		pollResultsCommandName:    PollResultsCommand{Db: db},
+		pollReminderCommandGroupName: CommandGroup{
+			reminderListCommandName:   PollReminderListCommand{Db: db, Settings: handler.Settings},
+			reminderCancelCommandName: PollReminderCancelCommand{Db: db, Settings: handler.Settings},
+		},
+		pollTagCommandGroupName: CommandGroup{
+			tagAddCommandName:    PollTagCommand{Db: db, Settings: handler.Settings, Add: true},
+			tagRemoveCommandName: PollTagCommand{Db: db, Settings: handler.Settings},
+		},
+		pollAnswerCommandGroupName: CommandGroup{
+			answerAddCommandName:    PollAnswerCommand{Db: db, Settings: handler.Settings, Add: true},
+			answerRemoveCommandName: PollAnswerCommand{Db: db, Settings: handler.Settings},
+		},
+		pollVotingCommandName:  PollVotingCommand{Db: db, Settings: handler.Settings},
+		pollRandomCommandName:  PollRandomCommand{Db: db},
+		pollPreviewCommandName: PollPreviewCommand{Db: db},
+		pollDailyCommandGroupName: CommandGroup{
+			dailySetCommandName:   DailyScheduleCommand{Db: db, Settings: handler.Settings},
+			dailyClearCommandName: DailyScheduleCommand{Db: db, Settings: handler.Settings, Clear: true},
+		},
 	}
 }
 
@@ -48,16 +92,117 @@ type PollPostCommand struct {
 }
 
 func (p PollPostCommand) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
-	textChannel := i.ApplicationCommandData().Options[0].Options[1].ChannelValue(s)
-	if textChannel == nil {
-		return nil, fmt.Errorf("poll: missing channel argument")
+	args := parseInteractionInput(*i.Interaction)
+	id, _ := args["id"].(float64)
+	pollID := int64(id)
+
+	targets, err := findPostTargets(ctx, s, i.GuildID, args)
+	if err != nil {
+		return nil, err
+	}
+
+	guildSettings, err := p.PollMessageHandler.Settings.Get(ctx, i.GuildID)
+	if err != nil {
+		return nil, err
+	}
+
+	thread, ok := args["thread"].(bool)
+	if !ok {
+		thread = guildSettings.PollThreads
+	}
+
+	l.InfoContext(ctx, "valid poll post request received", "requestPollID", pollID, "requestChannels", len(targets))
+
+	req := postRequest{
+		GuildID: i.GuildID,
+		PollID:  pollID,
+		Channel: targets[0],
+		ActorID: interactionUserID(i),
+		Poster:  interactionUserName(i),
+		Thread:  thread,
+	}
+
+	if req.Reminders, req.ReminderRoleID, req.MinTurnout, err = parseReminderArgs(args); err != nil {
+		return nil, err
+	}
+
+	if err = checkReminderRole(ctx, s, i, guildSettings, req.ReminderRoleID); err != nil {
+		return nil, err
+	}
+
+	if req.Quorum, err = parseQuorumArgs(args); err != nil {
+		return nil, err
+	}
+
+	if err = parseEngineArgs(args, &req); err != nil {
+		return nil, err
+	}
+
+	if req.Weights, err = parseWeightArgs(ctx, s, i.GuildID, args); err != nil {
+		return nil, err
+	}
+
+	if len(targets) > 1 {
+		req.BatchID = i.ID
+		results := postPollToTargets(ctx, l, s, p.Db, req, targets)
+
+		return CreateSimpleDiscordResponse(createPostSummary(pollID, results) + reminderSummary(req.Reminders)), nil
+	}
+
+	msg, err := postPoll(ctx, l, s, p.Db, req)
+	if err != nil {
+		return nil, err
 	}
 
-	pollID := int64(i.ApplicationCommandData().Options[0].Options[0].FloatValue())
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("Model #%d was created here", pollID) + threadMention(msg) + reminderSummary(req.Reminders),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	}, nil
+}
+
+// Deferred is true for posting poll in many channels, because it takes more time than Discord waits for response
+func (p PollPostCommand) Deferred(i *discordgo.InteractionCreate) bool {
+	return isMultiTarget(parseInteractionInput(*i.Interaction))
+}
 
-	l.InfoContext(ctx, "valid poll post request received", "requestPollID", pollID, "requestChannelID", textChannel.ID)
+// postRequest describes poll posted from template by user or scheduled job
+type postRequest struct {
+	GuildID string
+	PollID  int64
+	// RevisionID rejects post, when template was edited after it was shown to user. Zero posts the latest revision
+	RevisionID int64
+	Channel    *discordgo.Channel
+	ActorID    string
+	Poster     string
+	// BatchID is set, when the same poll is posted in many channels at once
+	BatchID string
+	// Thread starts discussion thread from posted poll
+	Thread bool
+	// Reminders ping ReminderRoleID before poll closes, unless poll has MinTurnout votes
+	Reminders      []poll.ReminderLead
+	ReminderRoleID string
+	MinTurnout     int32
+	Quorum         poll.Quorum
+	// Engine renders poll. Hours, Anonymous and VoterRoleID are used only by buttons engine
+	Engine      poll.Engine
+	Hours       int
+	Anonymous   bool
+	VoterRoleID string
+	// Weights multiply votes of role members in poll posted with buttons engine
+	Weights []poll.RoleWeight
+	// AllowOther adds free-text answer to poll posted with buttons engine
+	AllowOther bool
+	// Count is number of previous posts shared by copies of batch. It's counted by postPoll, when nil
+	Count *int64
+}
 
-	po, err := p.Db.FindPoll(ctx, i.GuildID, pollID, "")
+// postPoll renders poll template, sends it to channel and records it in post history. Started discussion thread is
+// set as message's thread
+func postPoll(ctx context.Context, l *slog.Logger, s *discordgo.Session, db poll.Queries, req postRequest) (*discordgo.Message, error) {
+	po, err := db.FindPoll(ctx, req.GuildID, req.PollID, "")
 	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
 		return nil, MessageErr{
 			error:       err,
@@ -70,59 +215,176 @@ func (p PollPostCommand) HandleSlashCommand(ctx context.Context, l *slog.Logger,
 
 	l.InfoContext(ctx, "poll found", "pollID", po.ID, "pollQuestion", po.Question)
 
-	discordPoll, err := createDiscordPoll(po)
-	if err != nil {
+	if req.RevisionID != 0 && req.RevisionID != po.RevisionID {
+		return nil, MessageErr{CommandName: "post", Msg: "Poll was edited after preview. Preview it again"}
+	}
+
+	if err = resolveEngine(&req, po.Method); err != nil {
 		return nil, err
 	}
 
-	if _, err := s.ChannelMessageSendComplex(textChannel.ID, &discordgo.MessageSend{
-		Poll: &discordPoll,
-	}); err != nil {
+	if err = checkEmojis(ctx, s, req.GuildID, po.Answers); errors.Is(err, errUnknownEmoji) {
+		return nil, MessageErr{error: err, CommandName: "post", Msg: "Poll wasn't posted: " + err.Error()}
+	} else if err != nil {
 		return nil, err
 	}
 
-	l.InfoContext(ctx, fmt.Sprintf("poll posted on channel #%s(%s)", textChannel.Name, textChannel.ID), "pollID", pollID)
+	if req.Count == nil {
+		count, err := db.CountPosts(ctx, req.PollID)
+		if err != nil {
+			return nil, err
+		}
+		req.Count = &count
+	}
 
-	return &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content: fmt.Sprintf("Model #%d was created here", pollID),
-			Flags:   discordgo.MessageFlagsEphemeral,
-		},
-	}, nil
-}
+	vars := poll.Variables{
+		Now:     time.Now(),
+		Channel: req.Channel.Name,
+		Poster:  req.Poster,
+		Count:   *req.Count,
+	}
+	duration := time.Duration(po.Duration) * time.Hour
+	if req.Hours > 0 {
+		duration = time.Duration(req.Hours) * time.Hour
+	}
+
+	send, custom, err := createPollMessage(po, vars, req, vars.Now.Add(duration))
+	if errors.Is(err, poll.ErrInvalidPlaceholder) || errors.Is(err, errTooManyAnswers) {
+		return nil, MessageErr{error: err, CommandName: "post", Msg: "Poll wasn't posted: " + err.Error()}
+	} else if err != nil {
+		return nil, err
+	}
+
+	for _, lead := range req.Reminders {
+		if _, err = lead.RemindAt(vars.Now, vars.Now.Add(duration)); err != nil {
+			return nil, MessageErr{error: err, CommandName: "post", Msg: "Poll wasn't posted: " + err.Error()}
+		}
+	}
 
-func createDiscordPoll(p poll.Model) (dp discordgo.Poll, err error) {
-	answers := make([]discordgo.PollAnswer, len(p.Options))
-	for i, opt := range p.Options {
-		var (
-			text, emoji string
-		)
-		textWithEmoji := strings.Split(opt, "  ")
-		if len(textWithEmoji) == 1 {
-			text = textWithEmoji[1]
-		} else if len(textWithEmoji) == 2 {
-			text = textWithEmoji[1]
-			emoji = textWithEmoji[0]
+	msg, err := s.ChannelMessageSendComplex(req.Channel.ID, send, discordgo.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	l.InfoContext(ctx, fmt.Sprintf("poll posted on channel #%s(%s)", req.Channel.Name, req.Channel.ID), "pollID", req.PollID)
+
+	postedAt, closesAt := vars.Now, vars.Now.Add(duration)
+	if !msg.Timestamp.IsZero() {
+		postedAt = msg.Timestamp
+	}
+	if msg.Poll != nil && msg.Poll.Expiry != nil {
+		closesAt = *msg.Poll.Expiry
+	}
+
+	var question string
+	if custom != nil {
+		question = custom.Question
+	} else {
+		question = send.Poll.Question.Text
+	}
+
+	var threadID string
+	if req.Thread {
+		msg.Thread, err = s.MessageThreadStartComplex(msg.ChannelID, msg.ID, &discordgo.ThreadStart{
+			Name:                threadName(question),
+			AutoArchiveDuration: threadAutoArchiveDuration,
+		}, discordgo.WithContext(ctx))
+		if err != nil {
+			// Poll is already posted, so missing thread doesn't fail whole command
+			l.WarnContext(ctx, "failed start discussion thread", "error", err)
 		} else {
-			err = errors.New("invalid poll option. Option cannot be empty")
-			return
+			threadID = msg.Thread.ID
 		}
+	}
 
-		answers[i].Media = &discordgo.PollMedia{
-			Text: text,
+	post := poll.PostParams{
+		PollID:     req.PollID,
+		RevisionID: po.RevisionID,
+		GuildID:    req.GuildID,
+		ChannelID:  msg.ChannelID,
+		MessageID:  msg.ID,
+		ActorID:    req.ActorID,
+		BatchID:    req.BatchID,
+		ThreadID:   threadID,
+		ClosesAt:   closesAt,
+		Quorum:     req.Quorum,
+		Engine:     req.Engine,
+		Weights:    req.Weights,
+		Reminders:  createReminders(req, msg, postedAt, closesAt),
+	}
+	postID, err := db.RecordPost(ctx, post)
+	if err == nil && custom != nil {
+		custom.PostID, custom.MessageID = postID, msg.ID
+		err = db.CreateCustomPoll(ctx, *custom)
+	}
+	if err != nil && (custom != nil || len(req.Reminders) > 0) {
+		// Votes of poll posted with buttons engine and reminders can't be saved without post, so broken message is
+		// removed
+		if deleteErr := s.ChannelMessageDelete(msg.ChannelID, msg.ID, discordgo.WithContext(ctx)); deleteErr != nil {
+			l.WarnContext(ctx, "failed remove poll, which wasn't recorded", "error", deleteErr)
 		}
+		return nil, MessageErr{error: err, CommandName: "post", Msg: "Poll wasn't posted, because it couldn't be saved. Try again later"}
+	} else if err != nil {
+		l.WarnContext(ctx, "failed record posted poll", "error", err)
+	}
 
-		if emoji != "" && len(emoji) >= 0 {
-			answers[i].Media.Emoji = &discordgo.ComponentEmoji{
-				Name: emoji,
-			}
+	return msg, nil
+}
+
+// Discord limits of poll's question and answer length
+const (
+	maxQuestionLength = 300
+	maxAnswerLength   = 55
+	maxNativeAnswers  = 10
+)
+
+// renderPoll renders placeholders in question and answers of template
+func renderPoll(p poll.Model, vars poll.Variables) (question string, answers []poll.Answer, err error) {
+	question, err = poll.Render(p.Question, vars)
+	if err != nil {
+		return "", nil, fmt.Errorf("question: %w", err)
+	} else if utf8.RuneCountInString(question) > maxQuestionLength {
+		return "", nil, fmt.Errorf("%w: rendered question is longer than %d characters", poll.ErrInvalidPlaceholder, maxQuestionLength)
+	}
+
+	answers = make([]poll.Answer, len(p.Answers))
+	for i, answer := range p.Answers {
+		if answer.Text == "" {
+			return "", nil, errors.New("invalid poll option. Option cannot be empty")
+		}
+
+		if answer.Text, err = poll.Render(answer.Text, vars); err != nil {
+			return "", nil, fmt.Errorf("answer %d: %w", i+1, err)
+		} else if utf8.RuneCountInString(answer.Text) > maxAnswerLength {
+			return "", nil, fmt.Errorf("%w: rendered answer %d is longer than %d characters", poll.ErrInvalidPlaceholder, i+1, maxAnswerLength)
+		}
+
+		answers[i] = answer
+	}
+
+	return question, answers, nil
+}
+
+// createDiscordPoll renders placeholders in question and answers. Poll mustn't be posted if it returns an error
+func createDiscordPoll(p poll.Model, vars poll.Variables) (dp discordgo.Poll, err error) {
+	question, rendered, err := renderPoll(p, vars)
+	if err != nil {
+		return dp, err
+	} else if len(rendered) > maxNativeAnswers {
+		return dp, fmt.Errorf("%w: native poll can have up to %d answers. Post it with buttons engine", errTooManyAnswers, maxNativeAnswers)
+	}
+
+	answers := make([]discordgo.PollAnswer, len(rendered))
+	for i, a := range rendered {
+		answers[i].Media = &discordgo.PollMedia{
+			Text:  a.Text,
+			Emoji: a.Emoji.Component(),
 		}
 	}
 
 	return discordgo.Poll{
 		Question: discordgo.PollMedia{
-			Text: p.Question,
+			Text: question,
 		},
 		Answers:          answers,
 		AllowMultiselect: p.IsMulti,
@@ -135,24 +397,40 @@ type PollListCommand struct {
 }
 
 func (p PollListCommand) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
-	var title string
+	args := parseInteractionInput(*i.Interaction)
+	title, _ := args["title"].(string)
 
-	if rawTitle, ok := parseInteractionInput(*i.Interaction)["title"]; !ok && (rawTitle != nil) {
-		return nil, fmt.Errorf("poll: missing title argument")
-	} else {
-		title = rawTitle.(string)
+	var tag string
+	if rawTag, ok := args["tag"].(string); ok && rawTag != "" {
+		var err error
+		if tag, err = poll.NormalizeTag(rawTag); err != nil {
+			return nil, MessageErr{error: err, CommandName: "list", Msg: "Invalid tag: " + rawTag}
+		}
 	}
 
-	polls, err := p.Db.FindAllPoll(ctx, i.GuildID, title, 0)
+	if title == "" && tag == "" {
+		return nil, MessageErr{CommandName: "list", Msg: "Pass title or tag argument"}
+	}
+
+	polls, err := p.Db.FindAllPoll(ctx, i.GuildID, poll.SearchParams{Title: title, Tag: tag, Mode: parseSearchMode(args)})
 	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
 		return nil, err
 	} else if len(polls) == 0 {
-		return CreateSimpleDiscordResponse("No found any poll with title: " + title), nil
+		return CreateSimpleDiscordResponse(fmt.Sprintf("No found any poll with title %q and tag %q", title, tag)), nil
 	}
 
 	return createPollDetails(ctx, nil, polls...), nil
 }
 
+// parseSearchMode reads mode option of /poll list. Question is matched by title by default
+func parseSearchMode(args map[string]any) poll.SearchMode {
+	if m, _ := args["mode"].(string); m == searchModeFullText {
+		return poll.SearchFullText
+	}
+
+	return poll.SearchTitle
+}
+
 type PollDetailsCommand struct {
 	Db poll.Queries
 }
@@ -182,7 +460,7 @@ func (c PollDetailsCommand) HandleSlashCommand(ctx context.Context, l *slog.Logg
 
 	l.InfoContext(ctx, "poll found", "pollID", p.ID, "pollQuestion", p.Question)
 
-	u, err := s.User(p.AuthorID)
+	u, err := s.User(p.AuthorID, discordgo.WithContext(ctx))
 	if err != nil {
 		l.WarnContext(ctx, "failed fetch user", "error", err)
 	}
@@ -204,6 +482,27 @@ func findIdAndTitleInInteractionArgs(i discordgo.Interaction) (id int64, title s
 	return
 }
 
+// pollDetailsDescription describes template. Part of question or answers matched by full-text search is shown too
+func pollDetailsDescription(po poll.Model) string {
+	options := make([]string, len(po.Answers))
+	for j, a := range po.Answers {
+		options[j] = fmt.Sprintf(" - %s", a)
+	}
+
+	description := fmt.Sprintf("**Question**: %s\n**Duration**: %s\n**Options**:\n%s", po.Question, time.Duration(int64(po.Duration)*int64(time.Hour)), strings.Join(options, "\n"))
+	if po.Method.NeedsButtons() {
+		description += "\n**Voting**: " + po.Method.String()
+	}
+	if len(po.Tags) > 0 {
+		description += "\n**Tags**: " + strings.Join(po.Tags, ", ")
+	}
+	if po.Snippet != "" {
+		description += "\n**Match**: …" + po.Snippet + "…"
+	}
+
+	return description
+}
+
 func createPollDetails(ctx context.Context, user *discordgo.User, p ...poll.Model) *discordgo.InteractionResponse {
 	var (
 		author discordgo.MessageEmbedAuthor
@@ -213,30 +512,30 @@ func createPollDetails(ctx context.Context, user *discordgo.User, p ...poll.Mode
 		author.IconURL = user.AvatarURL("")
 		author.Name = user.GlobalName
 
-		var err error
-		r, err := downloadImage(author.IconURL)
-		imgCtx, cancel := context.WithTimeout(ctx, time.Second)
-		defer cancel()
-
+		// Download is bounded by HTTP client timeout. Short timeout applies only to searching main color
+		r, err := downloadImage(ctx, author.IconURL)
 		if err != nil {
 			color = 0x0
-		} else if color, err = imageMainColor(imgCtx, r); err != nil {
-			color = 0x0
+		} else {
+			defer logger.LogCloser(r)
+
+			imgCtx, cancel := context.WithTimeout(ctx, time.Second)
+			defer cancel()
+
+			if color, err = imageMainColor(imgCtx, r); err != nil {
+				color = 0x0
+			}
 		}
 	}
 
 	pollCount := min(len(p), 10)
 	embeds := make([]*discordgo.MessageEmbed, pollCount)
 	for i, po := range p[:pollCount] {
-		for j, opt := range po.Options {
-			po.Options[j] = fmt.Sprintf(" - %s", opt)
-		}
-
 		embeds[i] = &discordgo.MessageEmbed{
 			Author:      &author,
 			Color:       int(color),
 			Title:       fmt.Sprintf("Model **#%d**", po.ID),
-			Description: fmt.Sprintf("**Question**: %s\n**Duration**: %s\n**Options**:\n%s", po.Question, time.Duration(int64(po.Duration)*int64(time.Hour)), strings.Join(po.Options, "\n")),
+			Description: pollDetailsDescription(po),
 			Footer: &discordgo.MessageEmbedFooter{
 				Text: fmt.Sprintf("Created at: %s", po.CreatedAt.Time.Format(time.RFC822)),
 			},
@@ -266,21 +565,57 @@ func (p PollRemoveCommand) HandleSlashCommand(ctx context.Context, l *slog.Logge
 		id = int64(rawId.(float64))
 	}
 
-	if err := p.Db.DeletePoll(ctx, id); err != nil {
+	err := p.Db.DeletePoll(ctx, i.GuildID, id, interactionUserID(i))
+	if errors.Is(err, poll.ErrPollNotFound) {
 		return nil, MessageErr{
 			error:       err,
 			CommandName: "remove",
 			Msg:         "Invalid poll ID",
 		}
+	} else if err != nil {
+		return nil, err
 	}
 
 	l.InfoContext(ctx, "poll removed", "pollID", id)
 
-	return CreateSimpleDiscordResponse("Model removed"), nil
+	return CreateSimpleDiscordResponse(fmt.Sprintf("Model removed. Use `/poll %s id:%d` to undo it", pollRestoreCommandName, id)), nil
+}
+
+type PollRestoreCommand struct {
+	Db poll.Queries
+}
+
+func (p PollRestoreCommand) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	rawId, ok := parseInteractionInput(*i.Interaction)["id"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("poll: missing id argument")
+	}
+	id := int64(rawId)
+
+	err := p.Db.RestorePoll(ctx, i.GuildID, id, interactionUserID(i))
+	if errors.Is(err, poll.ErrPollNotFound) {
+		return nil, MessageErr{
+			error:       err,
+			CommandName: "restore",
+			Msg:         "Deleted poll with this ID doesn't exist or it was permanently removed",
+		}
+	} else if errors.Is(err, poll.ErrPollExists) {
+		return nil, MessageErr{
+			error:       err,
+			CommandName: "restore",
+			Msg:         "Another poll with the same question exists. Remove it before restoring this one",
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	l.InfoContext(ctx, "poll restored", "pollID", id)
+
+	return CreateSimpleDiscordResponse(fmt.Sprintf("Model #%d restored", id)), nil
 }
 
 func NewPollCommandDefinition() *discordgo.ApplicationCommand {
-	return &discordgo.ApplicationCommand{
+	command := &discordgo.ApplicationCommand{
 		Name:        "poll",
 		Description: "Manage poll",
 		Type:        discordgo.ChatApplicationCommand,
@@ -310,9 +645,18 @@ func NewPollCommandDefinition() *discordgo.ApplicationCommand {
 					{
 						Type:        discordgo.ApplicationCommandOptionString,
 						Name:        "title",
-						Required:    true,
 						Description: "Find polls by specific name",
 					},
+					tagOption(false),
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "mode",
+						Description: "Match part of question or search question and answers ignoring typos and accents",
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{Name: "question contains title", Value: searchModeTitle},
+							{Name: "full-text search", Value: searchModeFullText},
+						},
+					},
 					{
 						Type:        discordgo.ApplicationCommandOptionInteger,
 						Name:        "page",
@@ -333,10 +677,71 @@ func NewPollCommandDefinition() *discordgo.ApplicationCommand {
 					},
 				},
 			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        pollRestoreCommandName,
+				Description: "Restore removed poll by id",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "id",
+						Required:    true,
+						Description: "Model's ID",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        pollHistoryCommandName,
+				Description: "Show revisions of poll and changes between them",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "id",
+						Required:    true,
+						Description: "Model's ID",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        pollRollbackCommandName,
+				Description: "Restore poll's question, settings and answers from older revision",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "id",
+						Required:    true,
+						Description: "Model's ID",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "revision",
+						Required:    true,
+						MinValue:    &minRevision,
+						Description: "Revision number from /poll history",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+				Name:        pollTagCommandGroupName,
+				Description: "Manage poll tags",
+				Options: []*discordgo.ApplicationCommandOption{
+					tagSubCommandOption(tagAddCommandName, "Tag poll", tagWeightOption()),
+					tagSubCommandOption(tagRemoveCommandName, "Remove tag from poll"),
+				},
+			},
 			{
 				Type:        discordgo.ApplicationCommandOptionSubCommand,
 				Name:        pollPostCommandName,
 				Description: "Post poll from template",
+				Options:     pollPostOptions(),
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        pollResultsCommandName,
+				Description: "Show results of the latest post combined across all channels",
 				Options: []*discordgo.ApplicationCommandOption{
 					{
 						Type:        discordgo.ApplicationCommandOptionInteger,
@@ -344,17 +749,29 @@ func NewPollCommandDefinition() *discordgo.ApplicationCommand {
 						Required:    true,
 						Description: "Model's ID",
 					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        pollImportCommandName,
+				Description: "Save poll from message link as template",
+				Options: []*discordgo.ApplicationCommandOption{
 					{
-						Type: discordgo.ApplicationCommandOptionChannel,
-						Name: "channel",
-						ChannelTypes: []discordgo.ChannelType{
-							discordgo.ChannelTypeGuildText,
-						},
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "link",
 						Required:    true,
-						Description: "Text channel where post will be posted",
+						Description: "Link to message with poll",
 					},
 				},
 			},
 		},
 	}
+	command.Options = append(command.Options, pollRandomCommandOptions()...)
+	command.Options = append(command.Options, pollReminderCommandOption())
+	command.Options = append(command.Options, pollAnswerCommandOption())
+	command.Options = append(command.Options, pollVotingCommandOption())
+	command.Options = append(command.Options, pollPreviewCommandOption())
+	command.Options = append(command.Options, pollExportCommandOptions()...)
+
+	return command
 }
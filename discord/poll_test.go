@@ -0,0 +1,109 @@
+package discord
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wittano/yomoid/poll"
+)
+
+func TestCreateDiscordPoll(t *testing.T) {
+	vars := poll.Variables{Now: time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC), Channel: "lunch"}
+	p := poll.Model{
+		Question: "Lunch on {{weekday +1d}} in {{channel}}?",
+		Duration: 24,
+		Answers: []poll.Answer{
+			{Text: "pizza {{date \"DD.MM\"}}", Emoji: poll.Emoji{Name: "🍕"}},
+			{Text: "kebab  rolls", Emoji: poll.Emoji{ID: "1234", Name: "kebab", Animated: true}},
+		},
+	}
+
+	dp, err := createDiscordPoll(p, vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dp.Question.Text != "Lunch on Tuesday in lunch?" {
+		t.Fatalf("invalid question %q", dp.Question.Text)
+	}
+
+	if a := dp.Answers[0].Media; a.Text != "pizza 19.10" || a.Emoji == nil || a.Emoji.Name != "🍕" {
+		t.Fatalf("invalid first answer %+v", a)
+	}
+
+	if a := dp.Answers[1].Media; a.Text != "kebab  rolls" || a.Emoji == nil || a.Emoji.ID != "1234" || !a.Emoji.Animated {
+		t.Fatalf("invalid second answer %+v", a)
+	}
+
+	p.Answers[1].Text = "{{unknown}}"
+	if _, err = createDiscordPoll(p, vars); !errors.Is(err, poll.ErrInvalidPlaceholder) {
+		t.Fatalf("expected invalid placeholder error, got: %v", err)
+	}
+}
+
+func TestParseSearchMode(t *testing.T) {
+	tests := []struct {
+		args map[string]any
+		want poll.SearchMode
+	}{
+		{nil, poll.SearchTitle},
+		{map[string]any{"mode": searchModeTitle}, poll.SearchTitle},
+		{map[string]any{"mode": searchModeFullText}, poll.SearchFullText},
+		{map[string]any{"mode": "unknown"}, poll.SearchTitle},
+	}
+
+	for _, tt := range tests {
+		if got := parseSearchMode(tt.args); got != tt.want {
+			t.Errorf("parseSearchMode(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestPollDetailsDescription(t *testing.T) {
+	base := poll.Model{Question: "Lunch?", Duration: 24, Answers: []poll.Answer{{Text: "pizza"}, {Text: "sushi"}}}
+
+	tests := []struct {
+		name    string
+		change  func(p *poll.Model)
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "title match",
+			change:  func(*poll.Model) {},
+			want:    []string{"**Question**: Lunch?", " - pizza\n - sushi"},
+			notWant: []string{"**Match**", "**Tags**", "**Voting**"},
+		},
+		{
+			name:   "full-text match",
+			change: func(p *poll.Model) { p.Snippet = "**pizza** sushi" },
+			want:   []string{"**Match**: …**pizza** sushi…"},
+		},
+		{
+			name:   "tags and voting method",
+			change: func(p *poll.Model) { p.Tags, p.Method = []string{"food", "daily"}, poll.Approval },
+			want:   []string{"**Tags**: food, daily", "**Voting**: approval voting"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := base
+			tt.change(&p)
+
+			description := pollDetailsDescription(p)
+			for _, w := range tt.want {
+				if !strings.Contains(description, w) {
+					t.Errorf("description doesn't contain %q:\n%s", w, description)
+				}
+			}
+			for _, w := range tt.notWant {
+				if strings.Contains(description, w) {
+					t.Errorf("description contains %q:\n%s", w, description)
+				}
+			}
+		})
+	}
+}
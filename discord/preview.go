@@ -0,0 +1,241 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/wittano/yomoid/poll"
+	"github.com/wittano/yomoid/settings"
+)
+
+const (
+	pollPreviewCommandName = "preview"
+
+	pollPreviewPostID   = "poll-preview-post"
+	pollPreviewCancelID = "poll-preview-cancel"
+)
+
+// PollPreviewCommand shows, how template will look like in channel. Poll is posted after confirmation
+type PollPreviewCommand struct {
+	Db poll.Queries
+}
+
+func (c PollPreviewCommand) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	args := parseInteractionInput(*i.Interaction)
+	id, _ := args["id"].(float64)
+
+	channelID, ok := args["channel"].(string)
+	if !ok {
+		channelID = i.ChannelID
+	}
+
+	channel, err := findChannel(ctx, s, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	po, err := c.Db.FindPoll(ctx, i.GuildID, int64(id), "")
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return nil, MessageErr{error: err, CommandName: "preview", Msg: "Invalid poll ID"}
+	} else if err != nil {
+		return nil, err
+	}
+
+	req := postRequest{GuildID: i.GuildID, PollID: po.ID, Channel: channel}
+	if err = resolveEngine(&req, po.Method); err != nil {
+		return nil, err
+	}
+
+	count, err := c.Db.CountPosts(ctx, po.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Placeholders are resolved like the poll was posted now
+	vars := poll.Variables{
+		Now:     time.Now(),
+		Channel: channel.Name,
+		Poster:  interactionUserName(i),
+		Count:   count,
+	}
+
+	send, custom, err := createPollMessage(po, vars, req, vars.Now.Add(time.Duration(po.Duration)*time.Hour))
+	if errors.Is(err, poll.ErrInvalidPlaceholder) || errors.Is(err, errTooManyAnswers) {
+		return nil, MessageErr{error: err, CommandName: "preview", Msg: "Poll can't be posted: " + err.Error()}
+	} else if err != nil {
+		return nil, err
+	}
+
+	emojiErr := checkEmojis(ctx, s, i.GuildID, po.Answers)
+	if emojiErr != nil && !errors.Is(emojiErr, errUnknownEmoji) {
+		return nil, emojiErr
+	}
+
+	l.InfoContext(ctx, "poll preview rendered", "pollID", po.ID, "engine", req.Engine)
+
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: createPreview(po, req, send, custom, emojiErr),
+	}, nil
+}
+
+// createPreview renders question and answers of message, which will be posted. Poll can't be posted, when emojiErr
+// isn't nil
+func createPreview(p poll.Model, req postRequest, send *discordgo.MessageSend, custom *poll.CustomPoll, emojiErr error) *discordgo.InteractionResponseData {
+	var (
+		question string
+		answers  []poll.Answer
+	)
+	if custom != nil {
+		question, answers = custom.Question, custom.Answers
+	} else {
+		question = send.Poll.Question.Text
+		for _, a := range send.Poll.Answers {
+			answers = append(answers, poll.Answer{Text: a.Media.Text, Emoji: poll.NewEmoji(a.Media.Emoji)})
+		}
+	}
+
+	var description strings.Builder
+	for n, a := range answers {
+		fmt.Fprintf(&description, "%d. %s\n", n+1, a)
+	}
+	if emojiErr != nil {
+		description.WriteString("\n⚠️ Poll can't be posted: " + emojiErr.Error())
+	}
+
+	multiselect := "no"
+	if p.IsMulti || p.Method == poll.Approval {
+		multiselect = "yes"
+	}
+
+	return &discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{
+			{
+				Title:       truncate(question, 256),
+				Description: description.String(),
+				Fields: []*discordgo.MessageEmbedField{
+					{Name: "Duration", Value: time.Duration(int64(p.Duration) * int64(time.Hour)).String(), Inline: true},
+					{Name: "Multiselect", Value: multiselect, Inline: true},
+					{Name: "Voting", Value: p.Method.String(), Inline: true},
+					{Name: "Engine", Value: string(req.Engine), Inline: true},
+					{Name: "Channel", Value: fmt.Sprintf("<#%s>", req.Channel.ID), Inline: true},
+				},
+				Footer: &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Preview of poll #%d", p.ID)},
+			},
+		},
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label:    truncate("Post to #"+req.Channel.Name, maxButtonLabel),
+						Style:    discordgo.SuccessButton,
+						CustomID: customID(pollPreviewPostID, strconv.FormatInt(p.ID, 10), req.Channel.ID, strconv.FormatInt(p.RevisionID, 10)),
+						Disabled: emojiErr != nil,
+					},
+					discordgo.Button{
+						Label:    "Cancel",
+						Style:    discordgo.SecondaryButton,
+						CustomID: pollPreviewCancelID,
+					},
+				},
+			},
+		},
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+		Flags:           discordgo.MessageFlagsEphemeral,
+	}
+}
+
+// PollPreviewPostButton posts previewed poll in channel passed in custom ID
+type PollPreviewPostButton struct {
+	Db       poll.Queries
+	Settings *settings.Service
+}
+
+func (b PollPreviewPostButton) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	_, args := parseCustomID(i.MessageComponentData().CustomID)
+	if len(args) != 3 {
+		return nil, fmt.Errorf("preview: invalid custom ID %q", i.MessageComponentData().CustomID)
+	}
+
+	pollID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("preview: invalid poll ID %q", args[0])
+	}
+
+	revisionID, err := strconv.ParseInt(args[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("preview: invalid revision ID %q", args[2])
+	}
+
+	channel, err := findChannel(ctx, s, args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	guildSettings, err := b.Settings.Get(ctx, i.GuildID)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := postPoll(ctx, l, s, b.Db, postRequest{
+		GuildID:    i.GuildID,
+		PollID:     pollID,
+		RevisionID: revisionID,
+		Channel:    channel,
+		ActorID:    interactionUserID(i),
+		Poster:     interactionUserName(i),
+		Thread:     guildSettings.PollThreads,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return createPreviewUpdateResponse(fmt.Sprintf("Model #%d was posted in <#%s>", pollID, channel.ID) + threadMention(msg)), nil
+}
+
+// PollPreviewCancelButton closes preview without posting poll
+type PollPreviewCancelButton struct{}
+
+func (PollPreviewCancelButton) HandleSlashCommand(context.Context, *slog.Logger, *discordgo.Session, *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	return createPreviewUpdateResponse("Poll wasn't posted"), nil
+}
+
+// createPreviewUpdateResponse replaces preview with content
+func createPreviewUpdateResponse(content string) *discordgo.InteractionResponse {
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    content,
+			Embeds:     []*discordgo.MessageEmbed{},
+			Components: []discordgo.MessageComponent{},
+		},
+	}
+}
+
+func pollPreviewCommandOption() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommand,
+		Name:        pollPreviewCommandName,
+		Description: "Show how poll will look like before posting it",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "id",
+				Required:    true,
+				Description: "Poll's ID",
+			},
+			{
+				Type:         discordgo.ApplicationCommandOptionChannel,
+				Name:         "channel",
+				ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+				Description:  "Text channel where poll will be posted. Current channel by default",
+			},
+		},
+	}
+}
@@ -0,0 +1,42 @@
+package discord
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/wittano/yomoid/poll"
+)
+
+func TestCreatePreview(t *testing.T) {
+	p := poll.Model{
+		ID:         7,
+		RevisionID: 3,
+		Question:   "Lunch in {{channel}}?",
+		Duration:   24,
+		Answers:    []poll.Answer{{Text: "pizza", Emoji: poll.Emoji{Name: "🍕"}}, {Text: "kebab"}},
+	}
+	req := postRequest{Channel: &discordgo.Channel{ID: "1", Name: "lunch"}, Engine: poll.EngineNative}
+	vars := poll.Variables{Now: time.Now(), Channel: "lunch"}
+
+	send, custom, err := createPollMessage(p, vars, req, vars.Now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := createPreview(p, req, send, custom, nil)
+	if embed := data.Embeds[0]; embed.Title != "Lunch in lunch?" || !strings.Contains(embed.Description, "1. 🍕 pizza") {
+		t.Fatalf("invalid preview embed: %+v", embed)
+	}
+
+	post := data.Components[0].(discordgo.ActionsRow).Components[0].(discordgo.Button)
+	if post.Disabled || post.Label != "Post to #lunch" || post.CustomID != customID(pollPreviewPostID, "7", "1", "3") {
+		t.Fatalf("invalid post button: %+v", post)
+	}
+
+	data = createPreview(p, req, send, custom, errUnknownEmoji)
+	if post = data.Components[0].(discordgo.ActionsRow).Components[0].(discordgo.Button); !post.Disabled {
+		t.Fatal("post button is enabled for poll with unknown emoji")
+	}
+}
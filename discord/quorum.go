@@ -0,0 +1,393 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/wittano/yomoid/poll"
+	"github.com/wittano/yomoid/tracing"
+)
+
+const (
+	// guildMembersPageSize is the biggest page of guild members returned by Discord
+	guildMembersPageSize = 1000
+	// pollVotersPageSize is the biggest page of voters of poll answer returned by Discord
+	pollVotersPageSize = 100
+)
+
+// parseQuorumArgs reads quorum options of /poll create. Percent quorum is resolved into number of voters, when poll
+// closes
+func parseQuorumArgs(args map[string]any) (q poll.Quorum, err error) {
+	count, _ := args["quorum"].(float64)
+	percent, _ := args["quorum-percent"].(float64)
+	q.RoleID, _ = args["quorum-role"].(string)
+	q.Repost, _ = args["quorum-repost"].(bool)
+
+	switch {
+	case count > 0 && percent > 0:
+		return q, MessageErr{CommandName: "post", Msg: "Pass quorum as number of voters or percent of role, not both"}
+	case count > 0:
+		q.Required = int32(count)
+		q.RoleID = ""
+	case percent > 0:
+		if q.RoleID == "" {
+			return q, MessageErr{CommandName: "post", Msg: "Pass role, which members are counted by quorum percent"}
+		}
+
+		q.Percent = int16(percent)
+	case q.RoleID != "" || q.Repost:
+		return q, MessageErr{CommandName: "post", Msg: "Pass quorum or quorum-percent option to set quorum"}
+	}
+
+	return q, nil
+}
+
+// countRoleMembers pages through guild members. Bot needs privileged server members intent enabled in Discord
+// Developer Portal. It's slow in big guilds, so it's called only by QuorumChecker
+func countRoleMembers(ctx context.Context, s *discordgo.Session, guildID, roleID string) (int, error) {
+	var (
+		count int
+		after string
+	)
+	for {
+		members, err := s.GuildMembers(guildID, after, guildMembersPageSize, discordgo.WithContext(ctx))
+		if err != nil {
+			return 0, err
+		}
+
+		for _, m := range members {
+			if countsToQuorum(m, guildID, roleID) {
+				count++
+			}
+		}
+
+		if len(members) < guildMembersPageSize {
+			return count, nil
+		}
+		after = members[len(members)-1].User.ID
+	}
+}
+
+// countsToQuorum checks if member has role. Bots don't vote
+func countsToQuorum(m *discordgo.Member, guildID, roleID string) bool {
+	if m.User != nil && m.User.Bot {
+		return false
+	}
+
+	return hasRole(m, guildID, roleID)
+}
+
+// hasRole checks if member has role. @everyone role has guild's ID and isn't listed in member's roles
+func hasRole(m *discordgo.Member, guildID, roleID string) bool {
+	return roleID == guildID || slices.Contains(m.Roles, roleID)
+}
+
+// quorumStatus describes quorum of posted poll in results
+func quorumStatus(p poll.Post) string {
+	switch {
+	case !p.Quorum.Enabled():
+		return ""
+	case p.QuorumMet == nil:
+		return fmt.Sprintf(", quorum %s", p.Quorum)
+	case *p.QuorumMet:
+		return fmt.Sprintf(", quorum %s reached ✅", p.Quorum)
+	default:
+		return fmt.Sprintf(", quorum %s not reached ❌", p.Quorum)
+	}
+}
+
+// QuorumChecker marks results of closed polls as valid or invalid and reposts polls without quorum
+type QuorumChecker struct {
+	Db       poll.Queries
+	Session  *discordgo.Session
+	Interval time.Duration
+	// Timeout of checking single poll. Counting voters and role members pages through Discord API, so it's longer
+	// than timeout of slash command
+	Timeout time.Duration
+}
+
+// Run blocks until ctx is cancelled
+func (c QuorumChecker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.checkClosed(ctx)
+		}
+	}
+}
+
+func (c QuorumChecker) checkClosed(ctx context.Context) {
+	posts, err := c.Db.FindPostsToCheckQuorum(ctx, time.Now())
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			slog.ErrorContext(ctx, "failed find closed polls with quorum", "error", err)
+		}
+		return
+	}
+
+	for _, post := range posts {
+		if ctx.Err() != nil {
+			return
+		}
+
+		c.check(ctx, post)
+	}
+}
+
+func (c QuorumChecker) check(ctx context.Context, post poll.Post) {
+	ctx, span := tracing.StartEvent(ctx, "job poll quorum", post.GuildID, post.ChannelID)
+	defer span.End()
+
+	l := slog.Default().With("pollID", post.PollID, "guildID", post.GuildID, "channelID", post.ChannelID, "messageID", post.MessageID)
+
+	if err := c.resolve(ctx, l, post); err != nil {
+		l.ErrorContext(ctx, "failed check poll quorum", "error", err)
+		tracing.RecordError(span, err)
+		c.retry(ctx, l, post)
+	}
+}
+
+// resolve counts voters, saves result of quorum and reposts poll without quorum
+func (c QuorumChecker) resolve(ctx context.Context, l *slog.Logger, post poll.Post) error {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	voters, err := c.countVoters(ctx, l, post)
+	if err != nil {
+		return fmt.Errorf("count voters: %w", err)
+	}
+
+	required := post.Quorum.Required
+	if required == 0 {
+		members, err := countRoleMembers(ctx, c.Session, post.GuildID, post.Quorum.RoleID)
+		var restErr *discordgo.RESTError
+		if errors.As(err, &restErr) {
+			// Missing access to members won't change in next check, so quorum is left unresolved
+			l.WarnContext(ctx, "failed count members of quorum role", "error", err)
+			return c.finishUnresolved(ctx, l, post, fmt.Sprintf("Poll closed with %d voters. ⚠️ Quorum %s wasn't checked, because I can't count members of role. Enable Server Members intent of bot", voters, post.Quorum))
+		} else if err != nil {
+			return fmt.Errorf("count members of quorum role: %w", err)
+		}
+
+		required = max(poll.RequiredVoters(post.Quorum.Percent, members), 1)
+	}
+
+	// Result is saved before reposting, so failed repost isn't retried in a loop
+	met := voters >= int64(required)
+	if err = c.Db.FinishQuorum(ctx, post.ID, required, &met); err != nil {
+		return fmt.Errorf("save quorum: %w", err)
+	}
+
+	l.InfoContext(ctx, "poll quorum checked", "voters", voters, "required", required, "quorumMet", met)
+
+	var repost *discordgo.Message
+	if !met && post.Quorum.Repost {
+		if repost, err = c.repost(ctx, l, post); err != nil {
+			l.ErrorContext(ctx, "failed repost poll without quorum", "error", err)
+		}
+	}
+
+	quorum := post.Quorum
+	quorum.Required = required
+	summary := fmt.Sprintf("Poll closed with %d voters. Quorum is %s. ✅ Result is valid", voters, quorum)
+	if !met {
+		summary = fmt.Sprintf("Poll closed with %d voters. Quorum is %s. ❌ Result is invalid, because quorum wasn't reached", voters, quorum)
+	}
+	if repost != nil {
+		summary += fmt.Sprintf(". Poll was posted again: https://discord.com/channels/%s/%s/%s", post.GuildID, repost.ChannelID, repost.ID)
+	}
+
+	c.sendSummary(ctx, l, post, summary)
+
+	return nil
+}
+
+// retry counts failed check of post. Quorum is left unresolved after maxJobAttempts failed checks, so the post
+// doesn't block checking next posts
+func (c QuorumChecker) retry(ctx context.Context, l *slog.Logger, post poll.Post) {
+	// Check interrupted by shutdown isn't counted
+	if ctx.Err() != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	attempts, err := c.Db.FailQuorum(ctx, post.ID)
+	if err != nil {
+		l.ErrorContext(ctx, "failed count failed quorum check", "error", err)
+		return
+	} else if attempts < maxJobAttempts {
+		return
+	}
+
+	l.WarnContext(ctx, "quorum check given up", "attempts", attempts)
+	summary := fmt.Sprintf("⚠️ Quorum %s wasn't checked, because checking failed %d times", post.Quorum, attempts)
+	if err = c.finishUnresolved(ctx, l, post, summary); err != nil {
+		l.ErrorContext(ctx, "failed save unresolved poll quorum", "error", err)
+	}
+}
+
+// finishUnresolved saves quorum, which couldn't be resolved, as checked without result
+func (c QuorumChecker) finishUnresolved(ctx context.Context, l *slog.Logger, post poll.Post, summary string) error {
+	if err := c.Db.FinishQuorum(ctx, post.ID, 0, nil); err != nil {
+		return fmt.Errorf("save quorum: %w", err)
+	}
+
+	c.sendSummary(ctx, l, post, summary)
+
+	return nil
+}
+
+// countVoters counts voters of native poll from Discord, so votes cast, when bot didn't receive gateway events, are
+// counted too. Voters recorded from events are counted, if Discord doesn't return voters. Votes of poll posted with
+// buttons engine are stored only in database
+func (c QuorumChecker) countVoters(ctx context.Context, l *slog.Logger, post poll.Post) (int64, error) {
+	if post.Engine != poll.EngineButtons {
+		voters, err := fetchPollVoters(ctx, c.Session, post.ChannelID, post.MessageID)
+		if err == nil {
+			return voters, nil
+		}
+
+		l.WarnContext(ctx, "failed fetch voters of poll. Voters recorded from events are counted", "error", err)
+	}
+
+	return c.Db.CountVoters(ctx, post.ID)
+}
+
+// fetchPollVoters returns number of unique voters of native poll
+func fetchPollVoters(ctx context.Context, s *discordgo.Session, channelID, messageID string) (int64, error) {
+	msg, err := s.ChannelMessage(channelID, messageID, discordgo.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	} else if msg.Poll == nil {
+		return 0, errors.New("discord: message doesn't have poll")
+	}
+
+	voters := make(map[string]struct{})
+	for _, a := range msg.Poll.Answers {
+		endpoint := discordgo.EndpointPollAnswerVoters(channelID, messageID, a.AnswerID)
+		query := url.Values{"limit": {strconv.Itoa(pollVotersPageSize)}}
+		for {
+			body, err := s.RequestWithBucketID(http.MethodGet, endpoint+"?"+query.Encode(), nil, endpoint, discordgo.WithContext(ctx))
+			if err != nil {
+				return 0, err
+			}
+
+			var page struct {
+				Users []*discordgo.User `json:"users"`
+			}
+			if err = json.Unmarshal(body, &page); err != nil {
+				return 0, err
+			}
+
+			for _, u := range page.Users {
+				voters[u.ID] = struct{}{}
+			}
+
+			if len(page.Users) < pollVotersPageSize {
+				break
+			}
+			query.Set("after", page.Users[len(page.Users)-1].ID)
+		}
+	}
+
+	return int64(len(voters)), nil
+}
+
+func (c QuorumChecker) sendSummary(ctx context.Context, l *slog.Logger, post poll.Post, summary string) {
+	_, err := c.Session.ChannelMessageSendComplex(post.ChannelID, &discordgo.MessageSend{
+		Content: summary,
+		Reference: &discordgo.MessageReference{
+			MessageID:       post.MessageID,
+			ChannelID:       post.ChannelID,
+			GuildID:         post.GuildID,
+			FailIfNotExists: new(bool),
+		},
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	}, discordgo.WithContext(ctx))
+	if err != nil {
+		l.WarnContext(ctx, "failed send quorum summary", "error", err)
+	}
+}
+
+// repost posts poll again once with the same quorum
+func (c QuorumChecker) repost(ctx context.Context, l *slog.Logger, post poll.Post) (*discordgo.Message, error) {
+	channel, err := findChannel(ctx, c.Session, post.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+
+	quorum := post.Quorum
+	quorum.Repost = false
+
+	bot := c.Session.State.User
+	req := postRequest{
+		GuildID: post.GuildID,
+		PollID:  post.PollID,
+		Channel: channel,
+		ActorID: bot.ID,
+		Poster:  bot.Username,
+		Quorum:  quorum,
+		Engine:  post.Engine,
+	}
+
+	if post.Engine == poll.EngineButtons {
+		cp, err := c.Db.FindCustomPoll(ctx, post.ChannelID, post.MessageID)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Anonymous, req.VoterRoleID, req.Weights, req.AllowOther = cp.Anonymous, cp.VoterRoleID, cp.Weights, cp.AllowOther
+		req.Hours = max(int(post.ClosesAt.Sub(post.PostedAt).Round(time.Hour)/time.Hour), 1)
+	}
+
+	return postPoll(ctx, l, c.Session, c.Db, req)
+}
+
+var (
+	minQuorum        float64 = 1
+	minQuorumPercent float64 = 1
+)
+
+func quorumOptions() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "quorum",
+			MinValue:    &minQuorum,
+			Description: "Minimal number of voters, which makes result valid",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "quorum-percent",
+			MinValue:    &minQuorumPercent,
+			MaxValue:    100,
+			Description: "Minimal percent of role members, who have to vote. Bot needs Server Members intent",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionRole,
+			Name:        "quorum-role",
+			Description: "Role, which members are counted by quorum percent",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "quorum-repost",
+			Description: "Post poll again, when quorum wasn't reached",
+		},
+	}
+}
@@ -0,0 +1,30 @@
+package discord
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestCountsToQuorum(t *testing.T) {
+	const guildID = "1"
+
+	member := &discordgo.Member{User: &discordgo.User{ID: "10"}, Roles: []string{"2"}}
+	bot := &discordgo.Member{User: &discordgo.User{ID: "11", Bot: true}, Roles: []string{"2"}}
+
+	if !countsToQuorum(member, guildID, "2") {
+		t.Fatal("member with role wasn't counted")
+	}
+
+	if countsToQuorum(member, guildID, "3") {
+		t.Fatal("member without role was counted")
+	}
+
+	if !countsToQuorum(member, guildID, guildID) {
+		t.Fatal("member wasn't counted as @everyone")
+	}
+
+	if countsToQuorum(bot, guildID, "2") {
+		t.Fatal("bot was counted")
+	}
+}
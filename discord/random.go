@@ -0,0 +1,315 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/wittano/yomoid/poll"
+	"github.com/wittano/yomoid/settings"
+	"github.com/wittano/yomoid/tracing"
+)
+
+const (
+	pollRandomCommandName     = "random"
+	pollDailyCommandGroupName = "daily"
+	dailySetCommandName       = "set"
+	dailyClearCommandName     = "clear"
+)
+
+// randomPollWindow is default time, in which posted poll isn't picked again by /poll random
+var randomPollWindow = 14 * 24 * time.Hour
+
+// SetRandomPollWindow changes default time, in which posted poll isn't picked again by /poll random and daily job
+func SetRandomPollWindow(window time.Duration) {
+	randomPollWindow = window
+}
+
+func avoidWindow(days int16) time.Duration {
+	if days <= 0 {
+		return randomPollWindow
+	}
+
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// PollRandomCommand posts random poll from tag pool
+type PollRandomCommand struct {
+	Db poll.Queries
+}
+
+func (c PollRandomCommand) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	args := parseInteractionInput(*i.Interaction)
+
+	tag, err := poll.NormalizeTag(fmt.Sprint(args["tag"]))
+	if err != nil {
+		return nil, MessageErr{error: err, CommandName: "random", Msg: err.Error()}
+	}
+
+	channel, err := findChannel(ctx, s, fmt.Sprint(args["channel"]))
+	if err != nil {
+		return nil, err
+	}
+
+	days, _ := args["avoid-days"].(float64)
+	pollID, err := c.Db.PickRandomPoll(ctx, i.GuildID, tag, time.Now().Add(-avoidWindow(int16(days))))
+	if errors.Is(err, poll.ErrNoPollCandidates) {
+		return nil, MessageErr{error: err, CommandName: "random", Msg: fmt.Sprintf("All polls tagged with %s were posted recently or tag doesn't exist", tag)}
+	} else if err != nil {
+		return nil, err
+	}
+
+	l.InfoContext(ctx, "random poll picked", "pollID", pollID, "tag", tag)
+
+	_, err = postPoll(ctx, l, s, c.Db, postRequest{
+		GuildID: i.GuildID,
+		PollID:  pollID,
+		Channel: channel,
+		ActorID: interactionUserID(i),
+		Poster:  interactionUserName(i),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return CreateSimpleDiscordResponse(fmt.Sprintf("Model #%d from %s posted in <#%s>", pollID, tag, channel.ID)), nil
+}
+
+// DailyScheduleCommand sets or removes daily random poll in channel
+type DailyScheduleCommand struct {
+	Db       poll.Queries
+	Settings *settings.Service
+	Clear    bool
+}
+
+func (c DailyScheduleCommand) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	guildSettings, err := c.Settings.Get(ctx, i.GuildID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isGuildAdmin(i.Member, guildSettings) {
+		return nil, MessageErr{CommandName: "daily", Msg: "You don't have permission to schedule polls"}
+	}
+
+	args := parseInteractionInput(*i.Interaction)
+	channelID, _ := args["channel"].(string)
+
+	if c.Clear {
+		err = c.Db.DeleteSchedule(ctx, i.GuildID, channelID)
+		if errors.Is(err, poll.ErrScheduleNotFound) {
+			return nil, MessageErr{error: err, CommandName: "daily", Msg: fmt.Sprintf("<#%s> doesn't have daily poll", channelID)}
+		} else if err != nil {
+			return nil, err
+		}
+
+		l.InfoContext(ctx, "daily poll removed", "scheduleChannelID", channelID)
+
+		return CreateSimpleDiscordResponse(fmt.Sprintf("Daily poll in <#%s> removed", channelID)), nil
+	}
+
+	tag, err := poll.NormalizeTag(fmt.Sprint(args["tag"]))
+	if err != nil {
+		return nil, MessageErr{error: err, CommandName: "daily", Msg: err.Error()}
+	}
+
+	hour, _ := args["hour"].(float64)
+	days, _ := args["avoid-days"].(float64)
+
+	schedule := poll.Schedule{
+		GuildID:   i.GuildID,
+		ChannelID: channelID,
+		Tag:       tag,
+		Hour:      int16(hour),
+		AvoidDays: int16(days),
+		NextRunAt: poll.NextRun(time.Now(), int16(hour)),
+		CreatedBy: interactionUserID(i),
+	}
+	if err = c.Db.SaveSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	l.InfoContext(ctx, "daily poll scheduled", "scheduleChannelID", channelID, "tag", tag, "hour", schedule.Hour)
+
+	return CreateSimpleDiscordResponse(fmt.Sprintf(
+		"Random poll tagged with %s will be posted in <#%s> every day at %02d:00 UTC. Next post <t:%d:R>",
+		tag, channelID, schedule.Hour, schedule.NextRunAt.Unix(),
+	)), nil
+}
+
+// DailyPoller posts scheduled random polls
+type DailyPoller struct {
+	Db       poll.Queries
+	Session  *discordgo.Session
+	Interval time.Duration
+	// Timeout of posting single poll
+	Timeout time.Duration
+}
+
+// Run blocks until ctx is cancelled
+func (p DailyPoller) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.runDue(ctx)
+		}
+	}
+}
+
+func (p DailyPoller) runDue(ctx context.Context) {
+	now := time.Now()
+
+	schedules, err := p.Db.FindDueSchedules(ctx, now)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			slog.ErrorContext(ctx, "failed find scheduled polls", "error", err)
+		}
+		return
+	}
+
+	for _, schedule := range schedules {
+		if ctx.Err() != nil {
+			return
+		}
+
+		p.run(ctx, schedule, now)
+	}
+}
+
+func (p DailyPoller) run(ctx context.Context, schedule poll.Schedule, now time.Time) {
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
+	defer cancel()
+
+	ctx, span := tracing.StartEvent(ctx, "job daily poll", schedule.GuildID, schedule.ChannelID)
+	defer span.End()
+
+	l := slog.Default().With("guildID", schedule.GuildID, "channelID", schedule.ChannelID, "tag", schedule.Tag)
+
+	// Next run is saved before posting, so failed post isn't retried in a loop
+	if err := p.Db.UpdateScheduleNextRun(ctx, schedule.ID, poll.NextRun(now, schedule.Hour)); err != nil {
+		l.ErrorContext(ctx, "failed update next run of daily poll", "error", err)
+		tracing.RecordError(span, err)
+		return
+	}
+
+	pollID, err := p.Db.PickRandomPoll(ctx, schedule.GuildID, schedule.Tag, now.Add(-avoidWindow(schedule.AvoidDays)))
+	if err != nil {
+		l.WarnContext(ctx, "failed pick daily poll", "error", err)
+		tracing.RecordError(span, err)
+		return
+	}
+
+	channel, err := findChannel(ctx, p.Session, schedule.ChannelID)
+	if err != nil {
+		l.ErrorContext(ctx, "failed find channel of daily poll", "error", err)
+		tracing.RecordError(span, err)
+		return
+	}
+
+	bot := p.Session.State.User
+	_, err = postPoll(ctx, l, p.Session, p.Db, postRequest{
+		GuildID: schedule.GuildID,
+		PollID:  pollID,
+		Channel: channel,
+		ActorID: bot.ID,
+		Poster:  bot.Username,
+	})
+	if err != nil {
+		l.ErrorContext(ctx, "failed post daily poll", "pollID", pollID, "error", err)
+		tracing.RecordError(span, err)
+	}
+}
+
+func findChannel(ctx context.Context, s *discordgo.Session, id string) (*discordgo.Channel, error) {
+	if c, err := s.State.Channel(id); err == nil {
+		return c, nil
+	}
+
+	c, err := s.Channel(id, discordgo.WithContext(ctx))
+	if err != nil {
+		return nil, messageAccessErr(err)
+	}
+
+	return c, nil
+}
+
+var (
+	minAvoidDays float64 = 0
+	minHour      float64 = 0
+)
+
+func avoidDaysOption() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionInteger,
+		Name:        "avoid-days",
+		MinValue:    &minAvoidDays,
+		MaxValue:    365,
+		Description: "Skip polls posted in the last days. Default is set by bot owner",
+	}
+}
+
+func textChannelOption(description string) *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:         discordgo.ApplicationCommandOptionChannel,
+		Name:         "channel",
+		ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+		Required:     true,
+		Description:  description,
+	}
+}
+
+func pollRandomCommandOptions() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        pollRandomCommandName,
+			Description: "Post random poll from tag",
+			Options: []*discordgo.ApplicationCommandOption{
+				tagOption(true),
+				textChannelOption("Text channel where post will be posted"),
+				avoidDaysOption(),
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+			Name:        pollDailyCommandGroupName,
+			Description: "Post random poll from tag every day",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        dailySetCommandName,
+					Description: "Schedule daily random poll in channel",
+					Options: []*discordgo.ApplicationCommandOption{
+						textChannelOption("Text channel where polls will be posted"),
+						tagOption(true),
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "hour",
+							Required:    true,
+							MinValue:    &minHour,
+							MaxValue:    23,
+							Description: "Hour of posting in UTC",
+						},
+						avoidDaysOption(),
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        dailyClearCommandName,
+					Description: "Stop posting daily poll in channel",
+					Options: []*discordgo.ApplicationCommandOption{
+						textChannelOption("Text channel with daily poll"),
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,385 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/wittano/yomoid/poll"
+	"github.com/wittano/yomoid/settings"
+	"github.com/wittano/yomoid/tracing"
+)
+
+const (
+	reminderListCommandName   = "list"
+	reminderCancelCommandName = "cancel"
+)
+
+// parseReminderArgs reads reminder options of /poll create. Empty leads mean, that poll doesn't have reminders
+func parseReminderArgs(args map[string]any) (leads []poll.ReminderLead, roleID string, minTurnout int32, err error) {
+	remind, _ := args["remind"].(string)
+	roleID, _ = args["remind-role"].(string)
+	turnout, _ := args["remind-turnout"].(float64)
+
+	if remind == "" {
+		if roleID != "" || turnout > 0 {
+			return nil, "", 0, MessageErr{CommandName: "post", Msg: "Pass remind option, e.g. `2h, 50%`, to schedule reminders"}
+		}
+
+		return nil, "", 0, nil
+	}
+
+	if roleID == "" {
+		return nil, "", 0, MessageErr{CommandName: "post", Msg: "Pass role, which will be pinged by reminder"}
+	}
+
+	leads, err = poll.ParseReminderLeads(remind)
+	if err != nil {
+		return nil, "", 0, MessageErr{error: err, CommandName: "post", Msg: err.Error()}
+	}
+
+	return leads, roleID, int32(turnout), nil
+}
+
+// checkReminderRole rejects role, which member can't ping. Reminders ping role explicitly, so they bypass
+// mentionable setting of role. Moderators can ping any role
+func checkReminderRole(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, guildSettings settings.Settings, roleID string) error {
+	if roleID == "" || isGuildAdmin(i.Member, guildSettings) {
+		return nil
+	}
+
+	roles, err := s.GuildRoles(i.GuildID, discordgo.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	idx := slices.IndexFunc(roles, func(r *discordgo.Role) bool { return r.ID == roleID })
+	if roleID == i.GuildID || idx < 0 || !roles[idx].Mentionable {
+		return MessageErr{CommandName: "post", Msg: fmt.Sprintf("Role <@&%s> isn't mentionable. Only moderators can ping it by reminders", roleID)}
+	}
+
+	return nil
+}
+
+// createReminders computes reminders of posted poll. Leads outside of actual poll duration are skipped
+func createReminders(req postRequest, msg *discordgo.Message, postedAt, closesAt time.Time) []poll.Reminder {
+	reminders := make([]poll.Reminder, 0, len(req.Reminders))
+	for _, lead := range req.Reminders {
+		at, err := lead.RemindAt(postedAt, closesAt)
+		if err != nil {
+			continue
+		}
+
+		reminders = append(reminders, poll.Reminder{
+			PollID:     req.PollID,
+			GuildID:    req.GuildID,
+			ChannelID:  msg.ChannelID,
+			MessageID:  msg.ID,
+			RoleID:     req.ReminderRoleID,
+			RemindAt:   at,
+			MinTurnout: req.MinTurnout,
+			CreatedBy:  req.ActorID,
+		})
+	}
+
+	return reminders
+}
+
+func reminderSummary(leads []poll.ReminderLead) string {
+	if len(leads) == 0 {
+		return ""
+	}
+
+	names := make([]string, len(leads))
+	for i, lead := range leads {
+		names[i] = lead.String()
+	}
+
+	return "\nReminders: " + strings.Join(names, ", ")
+}
+
+// checkReminderAdmin rejects members, who can't manage reminders
+func checkReminderAdmin(ctx context.Context, service *settings.Service, i *discordgo.InteractionCreate) error {
+	guildSettings, err := service.Get(ctx, i.GuildID)
+	if err != nil {
+		return err
+	}
+
+	if !isGuildAdmin(i.Member, guildSettings) {
+		return MessageErr{CommandName: "reminder", Msg: "You don't have permission to manage reminders"}
+	}
+
+	return nil
+}
+
+// PollReminderListCommand shows pending reminders of guild
+type PollReminderListCommand struct {
+	Db       poll.Queries
+	Settings *settings.Service
+}
+
+func (c PollReminderListCommand) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	if err := checkReminderAdmin(ctx, c.Settings, i); err != nil {
+		return nil, err
+	}
+
+	reminders, err := c.Db.FindReminders(ctx, i.GuildID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(reminders) == 0 {
+		return CreateSimpleDiscordResponse("There aren't any pending reminders"), nil
+	}
+
+	var b strings.Builder
+	for _, r := range reminders {
+		link := fmt.Sprintf("https://discord.com/channels/%s/%s/%s", r.GuildID, r.ChannelID, r.MessageID)
+		fmt.Fprintf(&b, "`%d` <t:%d:R> pings <@&%s> about [poll #%d](%s)", r.ID, r.RemindAt.Unix(), r.RoleID, r.PollID, link)
+		if r.MinTurnout > 0 {
+			fmt.Fprintf(&b, ", skipped at %d votes", r.MinTurnout)
+		}
+		b.WriteString("\n")
+	}
+
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{
+				{
+					Title:       "Pending reminders",
+					Description: b.String(),
+				},
+			},
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	}, nil
+}
+
+// PollReminderCancelCommand removes pending reminder
+type PollReminderCancelCommand struct {
+	Db       poll.Queries
+	Settings *settings.Service
+}
+
+func (c PollReminderCancelCommand) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	if err := checkReminderAdmin(ctx, c.Settings, i); err != nil {
+		return nil, err
+	}
+
+	id, _ := parseInteractionInput(*i.Interaction)["id"].(float64)
+
+	err := c.Db.CancelReminder(ctx, i.GuildID, int64(id))
+	if errors.Is(err, poll.ErrReminderNotFound) {
+		return nil, MessageErr{error: err, CommandName: "reminder", Msg: fmt.Sprintf("Reminder `%d` doesn't exist or was already sent", int64(id))}
+	} else if err != nil {
+		return nil, err
+	}
+
+	l.InfoContext(ctx, "poll reminder cancelled", "reminderID", int64(id))
+
+	return CreateSimpleDiscordResponse(fmt.Sprintf("Reminder `%d` cancelled", int64(id))), nil
+}
+
+// ReminderSender pings roles before polls close
+type ReminderSender struct {
+	Db       poll.Queries
+	Session  *discordgo.Session
+	Interval time.Duration
+	// Timeout of sending single reminder
+	Timeout time.Duration
+}
+
+// Run blocks until ctx is cancelled
+func (r ReminderSender) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.sendDue(ctx)
+		}
+	}
+}
+
+func (r ReminderSender) sendDue(ctx context.Context) {
+	reminders, err := r.Db.FindDueReminders(ctx, time.Now())
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			slog.ErrorContext(ctx, "failed find due poll reminders", "error", err)
+		}
+		return
+	}
+
+	for _, reminder := range reminders {
+		if ctx.Err() != nil {
+			return
+		}
+
+		r.send(ctx, reminder)
+	}
+}
+
+func (r ReminderSender) send(ctx context.Context, reminder poll.Reminder) {
+	ctx, span := tracing.StartEvent(ctx, "job poll reminder", reminder.GuildID, reminder.ChannelID)
+	defer span.End()
+
+	l := slog.Default().With("reminderID", reminder.ID, "pollID", reminder.PollID, "guildID", reminder.GuildID, "channelID", reminder.ChannelID)
+
+	skip, reason, err := r.remind(ctx, reminder)
+	if err != nil && !isPermanentRESTErr(err) {
+		l.WarnContext(ctx, "failed send poll reminder", "error", err)
+		tracing.RecordError(span, err)
+		if !r.giveUp(ctx, l, reminder) {
+			return
+		}
+
+		skip, reason = true, "sending failed too many times"
+	} else if err != nil {
+		// Removed channel or missing permissions won't change in next run
+		l.WarnContext(ctx, "poll reminder can't be sent", "error", err)
+		skip, reason = true, "bot can't access channel"
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+
+	if err = r.Db.FinishReminder(ctx, reminder.ID, skip); err != nil {
+		l.ErrorContext(ctx, "failed mark poll reminder as finished", "error", err)
+		tracing.RecordError(span, err)
+		return
+	}
+
+	if skip {
+		l.InfoContext(ctx, "poll reminder skipped", "reason", reason)
+	} else {
+		l.InfoContext(ctx, "poll reminder sent")
+	}
+}
+
+// remind pings role of reminder, unless reminder is needless
+func (r ReminderSender) remind(ctx context.Context, reminder poll.Reminder) (bool, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+
+	msg, err := fetchPollMessage(ctx, r.Session, r.Db, reminder.ChannelID, reminder.MessageID)
+	if err != nil && !isRESTStatus(err, http.StatusNotFound) {
+		return false, "", err
+	}
+
+	skip, reason := skipReminder(msg, reminder.MinTurnout, time.Now())
+	if skip {
+		return true, reason, nil
+	}
+
+	_, err = r.Session.ChannelMessageSendComplex(reminder.ChannelID, &discordgo.MessageSend{
+		Content: fmt.Sprintf("<@&%s> poll **%s** closes %s. Votes so far: %d",
+			reminder.RoleID, msg.Poll.Question.Text, closeTime(msg), messageVotes(msg)),
+		Reference:       msg.Reference(),
+		AllowedMentions: &discordgo.MessageAllowedMentions{Roles: []string{reminder.RoleID}},
+	}, discordgo.WithContext(ctx))
+
+	return false, "", err
+}
+
+// giveUp counts failed sending of reminder. It reports, if reminder should be skipped after maxJobAttempts failures,
+// so it doesn't block sending next reminders
+func (r ReminderSender) giveUp(ctx context.Context, l *slog.Logger, reminder poll.Reminder) bool {
+	// Sending interrupted by shutdown isn't counted
+	if ctx.Err() != nil {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+
+	attempts, err := r.Db.FailReminder(ctx, reminder.ID)
+	if err != nil {
+		l.ErrorContext(ctx, "failed count failed sending of poll reminder", "error", err)
+		return false
+	}
+
+	return attempts >= maxJobAttempts
+}
+
+// skipReminder checks if reminder about poll in msg is needless. Removed message is passed as nil
+func skipReminder(msg *discordgo.Message, minTurnout int32, now time.Time) (bool, string) {
+	switch {
+	case msg == nil || msg.Poll == nil:
+		return true, "poll message was removed"
+	case (msg.Poll.Results != nil && msg.Poll.Results.Finalized) || (msg.Poll.Expiry != nil && !msg.Poll.Expiry.After(now)):
+		return true, "poll is closed"
+	case minTurnout > 0 && messageVotes(msg) >= int(minTurnout):
+		return true, "turnout threshold reached"
+	default:
+		return false, ""
+	}
+}
+
+func closeTime(msg *discordgo.Message) string {
+	if msg.Poll.Expiry == nil {
+		return "soon"
+	}
+
+	return fmt.Sprintf("<t:%d:R>", msg.Poll.Expiry.Unix())
+}
+
+var minReminderTurnout float64 = 1
+
+func reminderOptions() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "remind",
+			Description: "Ping role before poll closes, e.g. 2h or 50% of duration. Separate reminders by comma",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionRole,
+			Name:        "remind-role",
+			Description: "Role pinged by reminders. Only moderators can pick role, which isn't mentionable",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "remind-turnout",
+			MinValue:    &minReminderTurnout,
+			Description: "Skip reminders, when poll has at least that many votes",
+		},
+	}
+}
+
+func pollReminderCommandOption() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+		Name:        pollReminderCommandGroupName,
+		Description: "Manage reminders of posted polls",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        reminderListCommandName,
+				Description: "Show pending reminders",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        reminderCancelCommandName,
+				Description: "Cancel pending reminder",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "id",
+						Required:    true,
+						Description: "Reminder's ID from /poll reminder list",
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,37 @@
+package discord
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestSkipReminder(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	expiry := now.Add(time.Hour)
+	msg := &discordgo.Message{Poll: &discordgo.Poll{
+		Expiry:  &expiry,
+		Results: &discordgo.PollResults{AnswerCounts: []*discordgo.PollAnswerCount{{ID: 1, Count: 3}, {ID: 2, Count: 2}}},
+	}}
+
+	if skip, reason := skipReminder(msg, 0, now); skip {
+		t.Fatalf("reminder without threshold was skipped: %s", reason)
+	}
+
+	if skip, reason := skipReminder(msg, 6, now); skip {
+		t.Fatalf("reminder below threshold was skipped: %s", reason)
+	}
+
+	if skip, _ := skipReminder(msg, 5, now); !skip {
+		t.Fatal("reminder wasn't skipped after reaching threshold")
+	}
+
+	if skip, _ := skipReminder(msg, 0, expiry); !skip {
+		t.Fatal("reminder of closed poll wasn't skipped")
+	}
+
+	if skip, _ := skipReminder(nil, 0, now); !skip {
+		t.Fatal("reminder of removed poll wasn't skipped")
+	}
+}
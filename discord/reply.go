@@ -0,0 +1,270 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/wittano/yomoid/poll"
+	"github.com/wittano/yomoid/settings"
+)
+
+const (
+	customPollOtherID      = "cpoll-other"
+	customPollOtherModalID = "cpoll-other-modal"
+	customPollRepliesID    = "cpoll-replies"
+	customPollPromoteID    = "cpoll-promote"
+	otherReplyInput        = "reply"
+)
+
+// CustomPollOtherButton opens modal, where voter types free-text answer
+type CustomPollOtherButton struct {
+	Db poll.Queries
+}
+
+func (b CustomPollOtherButton) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	cp, err := findOpenCustomPoll(ctx, b.Db, i, i.Message.ID)
+	if err != nil {
+		return nil, err
+	} else if !cp.AllowOther {
+		return nil, MessageErr{CommandName: "vote", Msg: "This poll doesn't accept other answers"}
+	}
+
+	reply, err := b.Db.FindUserReply(ctx, cp.PostID, interactionUserID(i))
+	if err != nil {
+		return nil, err
+	}
+
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: customID(customPollOtherModalID, cp.MessageID),
+			Title:    "Other answer",
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    otherReplyInput,
+							Label:       "Your answer. Leave it empty to remove it",
+							Style:       discordgo.TextInputShort,
+							Value:       reply,
+							MaxLength:   maxAnswerLength,
+							Placeholder: truncate(cp.Question, 100),
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// CustomPollOtherModal saves free-text answer typed by voter
+type CustomPollOtherModal struct {
+	Db poll.Queries
+}
+
+func (m CustomPollOtherModal) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	data := i.ModalSubmitData()
+	_, args := parseCustomID(data.CustomID)
+	if len(args) != 1 {
+		return nil, fmt.Errorf("other: invalid custom ID %q", data.CustomID)
+	}
+
+	cp, err := findOpenCustomPoll(ctx, m.Db, i, args[0])
+	if err != nil {
+		return nil, err
+	} else if !cp.AllowOther {
+		return nil, MessageErr{CommandName: "vote", Msg: "This poll doesn't accept other answers"}
+	}
+
+	reply := strings.TrimSpace(modalTextValue(data, otherReplyInput))
+	exclusive := singleChoice(cp)
+	err = m.Db.SetReply(ctx, poll.ReplyParams{
+		PostID:    cp.PostID,
+		UserID:    interactionUserID(i),
+		Text:      reply,
+		Exclusive: exclusive,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	l.InfoContext(ctx, "custom poll reply saved", "postID", cp.PostID, "removed", reply == "")
+
+	if reply == "" {
+		return CreateSimpleDiscordResponse("Your other answer was removed"), nil
+	}
+
+	if exclusive {
+		// Other answer replaced vote for fixed answer
+		refreshCustomPoll(ctx, l, s, m.Db, cp)
+	}
+
+	return CreateSimpleDiscordResponse(fmt.Sprintf("Your answer **%s** was saved", reply)), nil
+}
+
+// singleChoice is true for polls, where free-text answer replaces vote for fixed answer
+func singleChoice(cp poll.CustomPoll) bool {
+	return cp.Method == poll.Plurality && !cp.IsMulti
+}
+
+// refreshCustomPoll renders current tally in poll message. It's used, when interaction doesn't respond with poll
+// message
+func refreshCustomPoll(ctx context.Context, l *slog.Logger, s *discordgo.Session, db poll.Queries, cp poll.CustomPoll) {
+	t, err := db.FindTally(ctx, cp.PostID, len(cp.Answers))
+	if err != nil {
+		l.WarnContext(ctx, "failed find tally of custom poll", "error", err)
+		return
+	}
+
+	embeds := []*discordgo.MessageEmbed{createCustomPollEmbed(cp, t, time.Now())}
+	_, err = s.ChannelMessageEditComplex(&discordgo.MessageEdit{ID: cp.MessageID, Channel: cp.ChannelID, Embeds: &embeds}, discordgo.WithContext(ctx))
+	if err != nil {
+		l.WarnContext(ctx, "failed refresh tally of custom poll", "error", err)
+	}
+}
+
+// CustomPollRepliesButton shows page of free-text answers to user, who posted poll, and to moderators
+type CustomPollRepliesButton struct {
+	Db       poll.Queries
+	Settings *settings.Service
+}
+
+func (b CustomPollRepliesButton) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	// Button on poll message opens the first page. Buttons of opened view pass message of poll and page
+	messageID, page, responseType := i.Message.ID, 0, discordgo.InteractionResponseChannelMessageWithSource
+	if _, args := parseCustomID(i.MessageComponentData().CustomID); len(args) == 2 {
+		messageID, responseType = args[0], discordgo.InteractionResponseUpdateMessage
+		page, _ = strconv.Atoi(args[1])
+	}
+
+	cp, err := b.Db.FindCustomPoll(ctx, i.ChannelID, messageID)
+	if errors.Is(err, poll.ErrCustomPollNotFound) {
+		return nil, MessageErr{error: err, CommandName: "replies", Msg: "This poll doesn't exist anymore"}
+	} else if err != nil {
+		return nil, err
+	}
+
+	guildSettings, err := b.Settings.Get(ctx, i.GuildID)
+	if err != nil {
+		return nil, err
+	}
+
+	moderator := isGuildAdmin(i.Member, guildSettings)
+	if !moderator && interactionUserID(i) != cp.PostedBy {
+		return nil, MessageErr{CommandName: "replies", Msg: "Only author of poll and moderators can read other answers"}
+	}
+
+	replies, total, err := b.Db.FindReplies(ctx, cp.PostID, max(page, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	return &discordgo.InteractionResponse{
+		Type: responseType,
+		Data: createRepliesView(cp, replies, total, max(page, 0), moderator),
+	}, nil
+}
+
+func createRepliesView(cp poll.CustomPoll, replies []poll.Reply, total int64, page int, moderator bool) *discordgo.InteractionResponseData {
+	pages := max(int((total+poll.RepliesPageSize-1)/poll.RepliesPageSize), 1)
+
+	var b strings.Builder
+	if len(replies) == 0 {
+		b.WriteString("Nobody typed other answer yet")
+	}
+	for _, r := range replies {
+		fmt.Fprintf(&b, "`%d×` %s\n", r.Count, r.Text)
+	}
+
+	components := []discordgo.MessageComponent{}
+	if moderator && len(replies) > 0 {
+		options := make([]discordgo.SelectMenuOption, len(replies))
+		for i, r := range replies {
+			options[i] = discordgo.SelectMenuOption{Label: r.Text, Value: r.Text, Description: fmt.Sprintf("%d replies", r.Count)}
+		}
+
+		components = append(components, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					MenuType:    discordgo.StringSelectMenu,
+					CustomID:    customID(customPollPromoteID, cp.MessageID),
+					Placeholder: "Add reply as answer of poll template",
+					Options:     options,
+				},
+			},
+		})
+	}
+	if pages > 1 {
+		components = append(components, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "Previous",
+					Style:    discordgo.SecondaryButton,
+					CustomID: customID(customPollRepliesID, cp.MessageID, strconv.Itoa(page-1)),
+					Disabled: page == 0,
+				},
+				discordgo.Button{
+					Label:    "Next",
+					Style:    discordgo.SecondaryButton,
+					CustomID: customID(customPollRepliesID, cp.MessageID, strconv.Itoa(page+1)),
+					Disabled: page+1 >= pages,
+				},
+			},
+		})
+	}
+
+	return &discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{
+			{
+				Title:       "Other answers: " + cp.Question,
+				Description: b.String(),
+				Footer:      &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Page %d of %d", page+1, pages)},
+			},
+		},
+		Components: components,
+		Flags:      discordgo.MessageFlagsEphemeral,
+	}
+}
+
+// CustomPollPromoteSelect adds free-text answer chosen by moderator to template of poll. Posted poll isn't changed
+type CustomPollPromoteSelect struct {
+	Db       poll.Queries
+	Settings *settings.Service
+}
+
+func (p CustomPollPromoteSelect) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	guildSettings, err := p.Settings.Get(ctx, i.GuildID)
+	if err != nil {
+		return nil, err
+	} else if !isGuildAdmin(i.Member, guildSettings) {
+		return nil, MessageErr{CommandName: "promote", Msg: "Only moderators can add answers to poll template"}
+	}
+
+	data := i.MessageComponentData()
+	_, args := parseCustomID(data.CustomID)
+	if len(args) != 1 || len(data.Values) != 1 {
+		return nil, fmt.Errorf("promote: invalid custom ID %q", data.CustomID)
+	}
+
+	cp, err := p.Db.FindCustomPoll(ctx, i.ChannelID, args[0])
+	if errors.Is(err, poll.ErrCustomPollNotFound) {
+		return nil, MessageErr{error: err, CommandName: "promote", Msg: "This poll doesn't exist anymore"}
+	} else if err != nil {
+		return nil, err
+	}
+
+	summary, err := changeTemplateAnswers(ctx, l, p.Db, i.GuildID, cp.PollID, interactionUserID(i), func(answers []poll.AnswerParams) ([]poll.AnswerParams, string, error) {
+		return addTemplateAnswer(answers, poll.AnswerParams{Text: data.Values[0]})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return CreateSimpleDiscordResponse(summary + ". It will be shown, when poll is posted again"), nil
+}
@@ -0,0 +1,52 @@
+package discord
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/wittano/yomoid/poll"
+)
+
+func TestCreateCustomPollComponentsOther(t *testing.T) {
+	cp := customPollWithAnswers(maxButtonAnswers)
+	cp.AllowOther = true
+
+	rows := createCustomPollComponents(cp, time.Now())
+	// 4 rows of buttons and row with other, replies and voters buttons
+	if len(rows) != 5 {
+		t.Fatalf("rows = %d, want 5", len(rows))
+	}
+
+	controls := rows[4].(discordgo.ActionsRow).Components
+	if len(controls) != 3 || controls[0].(discordgo.Button).CustomID != customPollOtherID {
+		t.Fatalf("invalid controls: %+v", controls)
+	}
+}
+
+func TestCreateRepliesView(t *testing.T) {
+	cp := customPollWithAnswers(2)
+	cp.MessageID = "1"
+	replies := []poll.Reply{{Text: "pizza", Count: 3}, {Text: "sushi", Count: 1}}
+
+	view := createRepliesView(cp, replies, poll.RepliesPageSize+1, 0, false)
+	if len(view.Components) != 1 {
+		t.Fatalf("rows = %d, want 1", len(view.Components))
+	}
+
+	pages := view.Components[0].(discordgo.ActionsRow).Components
+	if prev := pages[0].(discordgo.Button); !prev.Disabled {
+		t.Fatal("previous button of the first page is enabled")
+	}
+	if next := pages[1].(discordgo.Button); next.Disabled || next.CustomID != customID(customPollRepliesID, "1", "1") {
+		t.Fatalf("invalid next button: %+v", next)
+	}
+
+	view = createRepliesView(cp, replies, 2, 0, true)
+	if len(view.Components) != 1 {
+		t.Fatalf("rows = %d, want 1", len(view.Components))
+	}
+	if sm := view.Components[0].(discordgo.ActionsRow).Components[0].(discordgo.SelectMenu); len(sm.Options) != 2 {
+		t.Fatalf("options = %d, want 2", len(sm.Options))
+	}
+}
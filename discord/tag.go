@@ -0,0 +1,146 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/wittano/yomoid/poll"
+	"github.com/wittano/yomoid/settings"
+)
+
+const (
+	tagAddCommandName    = "add"
+	tagRemoveCommandName = "remove"
+)
+
+// PollTagCommand adds or removes tag of poll
+type PollTagCommand struct {
+	Db       poll.Queries
+	Settings *settings.Service
+	Add      bool
+}
+
+func (c PollTagCommand) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	guildSettings, err := c.Settings.Get(ctx, i.GuildID)
+	if err != nil {
+		return nil, err
+	} else if !isGuildAdmin(i.Member, guildSettings) {
+		return nil, MessageErr{CommandName: "tag", Msg: "You don't have permission to change tags of polls"}
+	}
+
+	args := parseInteractionInput(*i.Interaction)
+
+	id, ok := args["id"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("poll: missing id argument")
+	}
+
+	rawTag, _ := args["tag"].(string)
+	tag, err := poll.NormalizeTag(rawTag)
+	if err != nil {
+		return nil, MessageErr{error: err, CommandName: "tag", Msg: fmt.Sprintf("Invalid tag %q: %s", rawTag, err)}
+	}
+
+	weight := int16(poll.MinTagWeight)
+	if w, ok := args["weight"].(float64); ok {
+		weight = int16(w)
+	}
+
+	pollID := int64(id)
+	if c.Add {
+		err = c.Db.AddTag(ctx, i.GuildID, pollID, tag, weight)
+	} else {
+		err = c.Db.RemoveTag(ctx, i.GuildID, pollID, tag)
+	}
+
+	if errors.Is(err, poll.ErrPollNotFound) {
+		return nil, MessageErr{error: err, CommandName: "tag", Msg: "Invalid poll ID"}
+	} else if errors.Is(err, poll.ErrTagNotFound) {
+		return nil, MessageErr{error: err, CommandName: "tag", Msg: fmt.Sprintf("Model #%d doesn't have tag %s", pollID, tag)}
+	} else if err != nil {
+		return nil, err
+	}
+
+	l.InfoContext(ctx, "poll tags changed", "pollID", pollID, "tag", tag, "added", c.Add, "weight", weight)
+
+	if c.Add {
+		return CreateSimpleDiscordResponse(fmt.Sprintf("Model #%d tagged with %s (weight %d)", pollID, tag, weight)), nil
+	}
+
+	return CreateSimpleDiscordResponse(fmt.Sprintf("Tag %s removed from Model #%d", tag, pollID)), nil
+}
+
+// TagAutocomplete suggests tags used in guild
+type TagAutocomplete struct {
+	Db poll.Queries
+}
+
+func (a TagAutocomplete) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	var prefix string
+	if opt := focusedOption(i.ApplicationCommandData()); opt != nil {
+		prefix, _ = opt.Value.(string)
+	}
+
+	// Incomplete tag can be invalid, e.g. ends with whitespace, so it's only trimmed
+	if normalized, err := poll.NormalizeTag(prefix); err == nil {
+		prefix = normalized
+	}
+
+	tags, err := a.Db.FindTags(ctx, i.GuildID, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, len(tags))
+	for j, tag := range tags {
+		choices[j] = &discordgo.ApplicationCommandOptionChoice{Name: tag, Value: tag}
+	}
+
+	return createAutocompleteResponse(choices), nil
+}
+
+func tagOption(required bool) *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:         discordgo.ApplicationCommandOptionString,
+		Name:         "tag",
+		Required:     required,
+		Autocomplete: true,
+		MaxLength:    32,
+		Description:  "Poll tag",
+	}
+}
+
+var (
+	minTagWeight float64 = poll.MinTagWeight
+	maxTagWeight float64 = poll.MaxTagWeight
+)
+
+func tagSubCommandOption(name, description string, extra ...*discordgo.ApplicationCommandOption) *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommand,
+		Name:        name,
+		Description: description,
+		Options: append([]*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "id",
+				Required:    true,
+				Description: "Model's ID",
+			},
+			tagOption(true),
+		}, extra...),
+	}
+}
+
+func tagWeightOption() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionInteger,
+		Name:        "weight",
+		MinValue:    &minTagWeight,
+		MaxValue:    maxTagWeight,
+		Description: "Chance of picking poll by /poll random from this tag. Default 1",
+	}
+}
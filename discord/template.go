@@ -0,0 +1,264 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/wittano/yomoid/poll"
+	"github.com/wittano/yomoid/settings"
+)
+
+const (
+	saveTemplateCommandName = "Save poll as template"
+
+	templateOverwriteID   = "template-overwrite"
+	templateRenameID      = "template-rename"
+	templateRenameModalID = "template-rename-modal"
+	templateQuestionInput = "question"
+)
+
+// SaveTemplateCommand saves native poll from selected message as template
+type SaveTemplateCommand struct {
+	Db       poll.Queries
+	Settings *settings.Service
+}
+
+func (c SaveTemplateCommand) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	guildSettings, err := checkTemplateAdmin(ctx, c.Settings, i)
+	if err != nil {
+		return nil, err
+	}
+
+	data := i.ApplicationCommandData()
+	if data.Resolved == nil || data.Resolved.Messages[data.TargetID] == nil {
+		return nil, fmt.Errorf("discord: missing target message %s", data.TargetID)
+	}
+
+	msg := data.Resolved.Messages[data.TargetID]
+	if msg.Poll == nil {
+		return nil, MessageErr{CommandName: "template", Msg: "Selected message doesn't contain poll"}
+	}
+	msg.GuildID = i.GuildID
+
+	if c.Db.Exists(ctx, msg.Poll.Question.Text, i.GuildID) {
+		l.InfoContext(ctx, "template with the same question already exists", "pollQuestion", msg.Poll.Question.Text)
+
+		return createTemplateExistsResponse(msg), nil
+	}
+
+	params := poll.NewCreatePollParams(*msg, guildSettings.DefaultDuration)
+	params.ActorID = interactionUserID(i)
+
+	pollID, err := c.Db.CreatePoll(ctx, params)
+	if errors.Is(err, poll.ErrPollExists) {
+		return createTemplateExistsResponse(msg), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	l.InfoContext(ctx, "poll saved as template", "pollID", pollID)
+
+	return CreateSimpleDiscordResponse(fmt.Sprintf("Poll %s saved as template `%d`", msg.Poll.Question.Text, pollID)), nil
+}
+
+// TemplateOverwriteButton replaces existing template by poll from message passed in custom ID
+type TemplateOverwriteButton struct {
+	Db       poll.Queries
+	Settings *settings.Service
+}
+
+func (b TemplateOverwriteButton) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	guildSettings, err := checkTemplateAdmin(ctx, b.Settings, i)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := findTemplateMessage(ctx, s, i.GuildID, i.MessageComponentData().CustomID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := poll.NewCreatePollParams(*msg, guildSettings.DefaultDuration)
+	params.ActorID = interactionUserID(i)
+
+	pollID, err := b.Db.OverwritePoll(ctx, params)
+	if errors.Is(err, poll.ErrPollNotFound) {
+		return nil, MessageErr{error: err, CommandName: "template", Msg: "Template was removed in meantime. Save poll again"}
+	} else if err != nil {
+		return nil, err
+	}
+
+	l.InfoContext(ctx, "template overwritten", "pollID", pollID)
+
+	return createTemplateUpdateResponse(fmt.Sprintf("Template `%d` overwritten by poll %s", pollID, msg.Poll.Question.Text)), nil
+}
+
+// TemplateRenameButton opens modal, where user sets new question of template
+type TemplateRenameButton struct {
+	Settings *settings.Service
+}
+
+func (b TemplateRenameButton) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	if _, err := checkTemplateAdmin(ctx, b.Settings, i); err != nil {
+		return nil, err
+	}
+
+	_, args := parseCustomID(i.MessageComponentData().CustomID)
+
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: customID(templateRenameModalID, args...),
+			Title:    "Save poll under new question",
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:  templateQuestionInput,
+							Label:     "Question",
+							Style:     discordgo.TextInputShort,
+							Required:  true,
+							MaxLength: 300,
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// TemplateRenameModal saves poll from message passed in custom ID with question typed by user
+type TemplateRenameModal struct {
+	Db       poll.Queries
+	Settings *settings.Service
+}
+
+func (m TemplateRenameModal) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	guildSettings, err := checkTemplateAdmin(ctx, m.Settings, i)
+	if err != nil {
+		return nil, err
+	}
+
+	data := i.ModalSubmitData()
+	msg, err := findTemplateMessage(ctx, s, i.GuildID, data.CustomID)
+	if err != nil {
+		return nil, err
+	}
+
+	question := modalTextValue(data, templateQuestionInput)
+	if question == "" {
+		return nil, MessageErr{CommandName: "template", Msg: "Question cannot be empty"}
+	}
+
+	params := poll.NewCreatePollParams(*msg, guildSettings.DefaultDuration)
+	params.Question = question
+	params.ActorID = interactionUserID(i)
+
+	pollID, err := m.Db.CreatePoll(ctx, params)
+	if errors.Is(err, poll.ErrPollExists) {
+		return nil, MessageErr{error: err, CommandName: "template", Msg: fmt.Sprintf("Template with question %s already exists", question)}
+	} else if err != nil {
+		return nil, err
+	}
+
+	l.InfoContext(ctx, "poll saved as template under new question", "pollID", pollID, "pollQuestion", question)
+
+	return createTemplateUpdateResponse(fmt.Sprintf("Poll saved as template `%d` with question %s", pollID, question)), nil
+}
+
+func checkTemplateAdmin(ctx context.Context, service *settings.Service, i *discordgo.InteractionCreate) (settings.Settings, error) {
+	guildSettings, err := service.Get(ctx, i.GuildID)
+	if err != nil {
+		return guildSettings, err
+	}
+
+	if !isGuildAdmin(i.Member, guildSettings) {
+		return guildSettings, MessageErr{CommandName: "template", Msg: "You don't have permission to save poll templates"}
+	}
+
+	return guildSettings, nil
+}
+
+// findTemplateMessage fetches message with poll. Channel and message IDs are passed as custom ID arguments
+func findTemplateMessage(ctx context.Context, s *discordgo.Session, guildID, id string) (*discordgo.Message, error) {
+	_, args := parseCustomID(id)
+	if len(args) != 2 {
+		return nil, fmt.Errorf("discord: invalid template custom ID %q", id)
+	}
+
+	msg, err := s.ChannelMessage(args[0], args[1], discordgo.WithContext(ctx))
+	if err != nil {
+		return nil, MessageErr{error: err, CommandName: "template", Msg: "Poll message isn't available anymore"}
+	}
+
+	if msg.Poll == nil {
+		return nil, MessageErr{CommandName: "template", Msg: "Selected message doesn't contain poll"}
+	}
+	msg.GuildID = guildID
+
+	return msg, nil
+}
+
+func modalTextValue(data discordgo.ModalSubmitInteractionData, id string) string {
+	for _, c := range data.Components {
+		row, ok := c.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+
+		for _, rc := range row.Components {
+			if input, ok := rc.(*discordgo.TextInput); ok && input.CustomID == id {
+				return input.Value
+			}
+		}
+	}
+
+	return ""
+}
+
+func createTemplateExistsResponse(msg *discordgo.Message) *discordgo.InteractionResponse {
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("Template with question %s already exists", msg.Poll.Question.Text),
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.Button{
+							Label:    "Overwrite",
+							Style:    discordgo.DangerButton,
+							CustomID: customID(templateOverwriteID, msg.ChannelID, msg.ID),
+						},
+						discordgo.Button{
+							Label:    "Rename",
+							Style:    discordgo.SecondaryButton,
+							CustomID: customID(templateRenameID, msg.ChannelID, msg.ID),
+						},
+					},
+				},
+			},
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	}
+}
+
+// createTemplateUpdateResponse replaces ephemeral message with buttons
+func createTemplateUpdateResponse(content string) *discordgo.InteractionResponse {
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    content,
+			Components: []discordgo.MessageComponent{},
+		},
+	}
+}
+
+func NewSaveTemplateCommandDefinition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name: saveTemplateCommandName,
+		Type: discordgo.MessageApplicationCommand,
+	}
+}
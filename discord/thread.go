@@ -0,0 +1,146 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/wittano/yomoid/poll"
+	"github.com/wittano/yomoid/tracing"
+)
+
+const (
+	// maxThreadNameLength is Discord limit of channel name
+	maxThreadNameLength = 100
+	// threadAutoArchiveDuration is the longest inactivity time in minutes supported by Discord. Thread is archived
+	// earlier by ThreadArchiver, when poll closes
+	threadAutoArchiveDuration = 10080
+)
+
+// threadName derives thread name from rendered poll question
+func threadName(question string) string {
+	name := strings.Join(strings.Fields(question), " ")
+	if name == "" {
+		return "Poll discussion"
+	}
+
+	runes := []rune(name)
+	if len(runes) > maxThreadNameLength {
+		return strings.TrimSpace(string(runes[:maxThreadNameLength-1])) + "…"
+	}
+
+	return name
+}
+
+func threadMention(msg *discordgo.Message) string {
+	if msg == nil || msg.Thread == nil {
+		return ""
+	}
+
+	return fmt.Sprintf(", discussion in <#%s>", msg.Thread.ID)
+}
+
+// ThreadArchiver archives discussion threads of closed polls
+type ThreadArchiver struct {
+	Db       poll.Queries
+	Session  *discordgo.Session
+	Interval time.Duration
+	// Timeout of archiving single thread
+	Timeout time.Duration
+}
+
+// Run blocks until ctx is cancelled
+func (a ThreadArchiver) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.archiveClosed(ctx)
+		}
+	}
+}
+
+func (a ThreadArchiver) archiveClosed(ctx context.Context) {
+	posts, err := a.Db.FindThreadsToArchive(ctx, time.Now())
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			slog.ErrorContext(ctx, "failed find discussion threads of closed polls", "error", err)
+		}
+		return
+	}
+
+	for _, post := range posts {
+		if ctx.Err() != nil {
+			return
+		}
+
+		a.archive(ctx, post)
+	}
+}
+
+func (a ThreadArchiver) archive(ctx context.Context, post poll.Post) {
+	ctx, span := tracing.StartEvent(ctx, "job archive poll thread", post.GuildID, post.ThreadID)
+	defer span.End()
+
+	l := slog.Default().With("pollID", post.PollID, "channelID", post.ChannelID, "threadID", post.ThreadID)
+
+	if err := a.archiveThread(ctx, post); err != nil && !isPermanentRESTErr(err) {
+		l.WarnContext(ctx, "failed archive discussion thread", "error", err)
+		tracing.RecordError(span, err)
+		if !a.giveUp(ctx, l, post) {
+			return
+		}
+	} else if err != nil {
+		// Removed thread or missing Manage Threads permission won't change in next run
+		l.WarnContext(ctx, "discussion thread can't be archived", "error", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, a.Timeout)
+	defer cancel()
+
+	if err := a.Db.MarkThreadArchived(ctx, post.ID); err != nil {
+		l.ErrorContext(ctx, "failed mark discussion thread as archived", "error", err)
+		tracing.RecordError(span, err)
+		return
+	}
+
+	l.InfoContext(ctx, "discussion thread of closed poll archived")
+}
+
+func (a ThreadArchiver) archiveThread(ctx context.Context, post poll.Post) error {
+	ctx, cancel := context.WithTimeout(ctx, a.Timeout)
+	defer cancel()
+
+	archived := true
+	_, err := a.Session.ChannelEdit(post.ThreadID, &discordgo.ChannelEdit{Archived: &archived}, discordgo.WithContext(ctx))
+
+	return err
+}
+
+// giveUp counts failed archiving of thread. It reports, if thread should be marked as archived after maxJobAttempts
+// failures, so it doesn't block archiving next threads
+func (a ThreadArchiver) giveUp(ctx context.Context, l *slog.Logger, post poll.Post) bool {
+	// Archiving interrupted by shutdown isn't counted
+	if ctx.Err() != nil {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, a.Timeout)
+	defer cancel()
+
+	attempts, err := a.Db.FailThreadArchive(ctx, post.ID)
+	if err != nil {
+		l.ErrorContext(ctx, "failed count failed archiving of discussion thread", "error", err)
+		return false
+	}
+
+	return attempts >= maxJobAttempts
+}
@@ -0,0 +1,22 @@
+package discord
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestThreadName(t *testing.T) {
+	if name := threadName("  Lunch\non   Friday? "); name != "Lunch on Friday?" {
+		t.Fatalf("invalid thread name %q", name)
+	}
+
+	if name := threadName(""); name != "Poll discussion" {
+		t.Fatalf("invalid thread name of empty question %q", name)
+	}
+
+	name := threadName(strings.Repeat("ą", 150))
+	if utf8.RuneCountInString(name) != maxThreadNameLength || !strings.HasSuffix(name, "…") {
+		t.Fatalf("thread name wasn't truncated to %d characters: %q", maxThreadNameLength, name)
+	}
+}
@@ -0,0 +1,281 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/wittano/yomoid/poll"
+	"github.com/wittano/yomoid/settings"
+)
+
+const (
+	pollVotingCommandName = "voting"
+
+	customPollRankID      = "cpoll-rank"
+	customPollRankPickID  = "cpoll-rank-pick"
+	customPollRankResetID = "cpoll-rank-reset"
+)
+
+// resolveEngine picks engine of posted poll. Voting methods, which native polls can't express, are posted with
+// buttons engine by default
+func resolveEngine(req *postRequest, method poll.VotingMethod) error {
+	switch {
+	case req.Engine == "" && method.NeedsButtons():
+		req.Engine = poll.EngineButtons
+	case req.Engine == "":
+		req.Engine = poll.EngineNative
+	case req.Engine == poll.EngineNative && method.NeedsButtons():
+		return MessageErr{CommandName: "post", Msg: fmt.Sprintf("Poll uses %s, which needs buttons engine", method)}
+	}
+
+	if req.Engine == poll.EngineNative && (req.Hours > 0 || req.Anonymous || req.VoterRoleID != "" || len(req.Weights) > 0 || req.AllowOther) {
+		return MessageErr{CommandName: "post", Msg: "Options hours, anonymous, voter-role, weights and other need buttons engine"}
+	}
+
+	return nil
+}
+
+// createRankingResponse shows user's ranking of answers and select menu with answers, which weren't ranked yet
+func createRankingResponse(cp poll.CustomPoll, ranking []int, responseType discordgo.InteractionResponseType) *discordgo.InteractionResponse {
+	var content strings.Builder
+	if len(ranking) == 0 {
+		content.WriteString("Choose answers from the most preferred. Answers, which you don't rank, don't get your vote")
+	} else {
+		content.WriteString("Your ranking:\n")
+		for i, a := range ranking {
+			fmt.Fprintf(&content, "%d. %s\n", i+1, customAnswerLabel(cp.Answers[a]))
+		}
+	}
+
+	var options []discordgo.SelectMenuOption
+	for i, a := range cp.Answers {
+		if slices.Contains(ranking, i) {
+			continue
+		}
+
+		options = append(options, discordgo.SelectMenuOption{Label: a.Text, Value: strconv.Itoa(i), Emoji: a.Emoji.Component()})
+	}
+
+	components := []discordgo.MessageComponent{}
+	if len(options) > 0 {
+		components = append(components, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					MenuType:    discordgo.StringSelectMenu,
+					CustomID:    customID(customPollRankPickID, cp.MessageID),
+					Placeholder: fmt.Sprintf("Choose your choice #%d", len(ranking)+1),
+					Options:     options,
+				},
+			},
+		})
+	}
+	if len(ranking) > 0 {
+		components = append(components, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "Start over",
+					Style:    discordgo.DangerButton,
+					CustomID: customID(customPollRankResetID, cp.MessageID),
+				},
+			},
+		})
+	}
+
+	return &discordgo.InteractionResponse{
+		Type: responseType,
+		Data: &discordgo.InteractionResponseData{
+			Content:    content.String(),
+			Components: components,
+			Flags:      discordgo.MessageFlagsEphemeral,
+		},
+	}
+}
+
+// CustomPollRankButton opens ballot of ranked poll visible only to voter
+type CustomPollRankButton struct {
+	Db poll.Queries
+}
+
+func (b CustomPollRankButton) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	cp, err := findOpenCustomPoll(ctx, b.Db, i, i.Message.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	ranking, err := b.Db.FindUserVotes(ctx, cp.PostID, interactionUserID(i))
+	if err != nil {
+		return nil, err
+	}
+
+	return createRankingResponse(cp, ranking, discordgo.InteractionResponseChannelMessageWithSource), nil
+}
+
+// CustomPollRankPick appends answer chosen on ballot to user's ranking. Reset clears ranking
+type CustomPollRankPick struct {
+	Db    poll.Queries
+	Reset bool
+}
+
+func (p CustomPollRankPick) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	_, args := parseCustomID(i.MessageComponentData().CustomID)
+	if len(args) != 1 {
+		return nil, fmt.Errorf("rank: invalid custom ID %q", i.MessageComponentData().CustomID)
+	}
+
+	cp, err := findOpenCustomPoll(ctx, p.Db, i, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	userID := interactionUserID(i)
+	var ranking []int
+	if !p.Reset {
+		if ranking, err = p.Db.FindUserVotes(ctx, cp.PostID, userID); err != nil {
+			return nil, err
+		}
+
+		for _, v := range i.MessageComponentData().Values {
+			answer, err := strconv.Atoi(v)
+			if err != nil || answer < 0 || answer >= len(cp.Answers) {
+				return nil, fmt.Errorf("rank: invalid answer %q", v)
+			}
+
+			if !slices.Contains(ranking, answer) {
+				ranking = append(ranking, answer)
+			}
+		}
+	}
+
+	_, err = p.Db.SetVotes(ctx, poll.VoteParams{
+		PostID:  cp.PostID,
+		UserID:  userID,
+		Answers: ranking,
+		Ranked:  true,
+		Weight:  voterWeight(cp, i),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	l.InfoContext(ctx, "ranked ballot saved", "postID", cp.PostID, "ranking", ranking)
+
+	// Ballot is ephemeral message, so tally of poll is refreshed separately
+	refreshCustomPoll(ctx, l, s, p.Db, cp)
+
+	return createRankingResponse(cp, ranking, discordgo.InteractionResponseUpdateMessage), nil
+}
+
+// runoffSummary describes rounds of instant runoff
+func runoffSummary(answers []poll.Answer, r poll.RunoffResult) string {
+	var b strings.Builder
+	for n, round := range r.Rounds {
+		var votes []string
+		for i, v := range round.Votes {
+			if v >= 0 {
+				votes = append(votes, fmt.Sprintf("%s %d", answers[i].Text, v))
+			}
+		}
+
+		fmt.Fprintf(&b, "**Round %d**: %s", n+1, strings.Join(votes, ", "))
+		if round.Exhausted > 0 {
+			fmt.Fprintf(&b, ", %d exhausted ballots", round.Exhausted)
+		}
+		if len(round.Eliminated) > 0 {
+			b.WriteString(". Eliminated: " + answerNames(answers, round.Eliminated))
+		}
+		b.WriteString("\n")
+	}
+
+	switch len(r.Winners) {
+	case 0:
+		b.WriteString("Nobody ranked any answer")
+	case 1:
+		b.WriteString("**Winner**: " + answerNames(answers, r.Winners))
+	default:
+		b.WriteString("**Tie**: " + answerNames(answers, r.Winners))
+	}
+
+	return b.String()
+}
+
+func answerNames(answers []poll.Answer, indexes []int) string {
+	names := make([]string, len(indexes))
+	for i, idx := range indexes {
+		names[i] = answers[idx].Text
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// PollVotingCommand sets voting method of template
+type PollVotingCommand struct {
+	Db       poll.Queries
+	Settings *settings.Service
+}
+
+func (c PollVotingCommand) HandleSlashCommand(ctx context.Context, l *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.InteractionResponse, error) {
+	guildSettings, err := c.Settings.Get(ctx, i.GuildID)
+	if err != nil {
+		return nil, err
+	} else if !isGuildAdmin(i.Member, guildSettings) {
+		return nil, MessageErr{CommandName: "voting", Msg: "Only moderators can change voting method of poll"}
+	}
+
+	args := parseInteractionInput(*i.Interaction)
+	id, _ := args["id"].(float64)
+	rawMethod, _ := args["method"].(string)
+
+	method := poll.VotingMethod(rawMethod)
+	if !method.Valid() {
+		return nil, MessageErr{CommandName: "voting", Msg: "Unknown voting method " + rawMethod}
+	}
+
+	err = c.Db.SetVotingMethod(ctx, i.GuildID, int64(id), method, interactionUserID(i))
+	if errors.Is(err, poll.ErrPollNotFound) {
+		return nil, MessageErr{error: err, CommandName: "voting", Msg: "Invalid poll ID"}
+	} else if err != nil {
+		return nil, err
+	}
+
+	l.InfoContext(ctx, "poll voting method changed", "pollID", int64(id), "votingMethod", method)
+
+	msg := fmt.Sprintf("Poll `%d` uses %s", int64(id), method)
+	if method.NeedsButtons() {
+		msg += ". It's posted with buttons engine"
+	}
+
+	return CreateSimpleDiscordResponse(msg), nil
+}
+
+func pollVotingCommandOption() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommand,
+		Name:        pollVotingCommandName,
+		Description: "Set how votes of poll are counted",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "id",
+				Required:    true,
+				Description: "Poll's ID",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "method",
+				Required:    true,
+				Description: "Voting method",
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "single or multiple choice", Value: string(poll.Plurality)},
+					{Name: "approval voting", Value: string(poll.Approval)},
+					{Name: "ranked choice (instant runoff)", Value: string(poll.Ranked)},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,43 @@
+package discord
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wittano/yomoid/poll"
+)
+
+func TestResolveEngine(t *testing.T) {
+	req := postRequest{}
+	if err := resolveEngine(&req, poll.Ranked); err != nil || req.Engine != poll.EngineButtons {
+		t.Fatalf("ranked poll resolved to %q, error %v", req.Engine, err)
+	}
+
+	req = postRequest{}
+	if err := resolveEngine(&req, poll.Plurality); err != nil || req.Engine != poll.EngineNative {
+		t.Fatalf("plurality poll resolved to %q, error %v", req.Engine, err)
+	}
+
+	req = postRequest{Engine: poll.EngineNative}
+	if err := resolveEngine(&req, poll.Approval); err == nil {
+		t.Fatal("approval poll was posted as native poll")
+	}
+
+	req = postRequest{Anonymous: true}
+	if err := resolveEngine(&req, poll.Plurality); err == nil {
+		t.Fatal("anonymous native poll was accepted")
+	}
+}
+
+func TestRunoffSummary(t *testing.T) {
+	answers := []poll.Answer{{Text: "a"}, {Text: "b"}, {Text: "c"}}
+	summary := runoffSummary(answers, poll.InstantRunoff(3, []poll.Ballot{
+		{Ranking: []int{0}}, {Ranking: []int{0}}, {Ranking: []int{1, 0}}, {Ranking: []int{2, 1}},
+	}))
+
+	for _, want := range []string{"**Round 1**: a 2, b 1, c 1. Eliminated: b, c", "**Round 2**: a 3", "**Winner**: a"} {
+		if !strings.Contains(summary, want) {
+			t.Fatalf("summary %q doesn't contain %q", summary, want)
+		}
+	}
+}
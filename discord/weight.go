@@ -0,0 +1,132 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/wittano/yomoid/poll"
+)
+
+// parseWeightArgs reads weights option of PollPostCommand. Weighted roles must exist in guild
+func parseWeightArgs(ctx context.Context, s *discordgo.Session, guildID string, args map[string]any) ([]poll.RoleWeight, error) {
+	raw, _ := args["weights"].(string)
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	weights, err := poll.ParseRoleWeights(raw)
+	if err != nil {
+		return nil, MessageErr{error: err, CommandName: "post", Msg: err.Error()}
+	}
+
+	roles, err := s.GuildRoles(guildID, discordgo.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, w := range weights {
+		if w.RoleID == guildID || !slices.ContainsFunc(roles, func(r *discordgo.Role) bool { return r.ID == w.RoleID }) {
+			return nil, MessageErr{CommandName: "post", Msg: fmt.Sprintf("Role <@&%s> can't be weighted. Pass role of this server other than @everyone", w.RoleID)}
+		}
+	}
+
+	return weights, nil
+}
+
+// voterWeight is weight of user, who clicked poll. Votes outside of guild count once
+func voterWeight(cp poll.CustomPoll, i *discordgo.InteractionCreate) int16 {
+	if i.Member == nil {
+		return 1
+	}
+
+	return poll.VoterWeight(cp.Weights, i.Member.Roles)
+}
+
+// findCustomResults describes results, which native polls don't have, of all copies of poll posted with buttons
+// engine: weighted tally and rounds of instant runoff. Empty summary means posts don't have such results. Weighted
+// tallies are summed, so all copies must have the same role weights
+func findCustomResults(ctx context.Context, db poll.Queries, posts []poll.Post) (string, error) {
+	var (
+		cp       poll.CustomPoll
+		postIDs  []int64
+		weighted []int
+	)
+	for _, p := range posts {
+		if p.Engine != poll.EngineButtons {
+			continue
+		}
+
+		c, err := db.FindCustomPoll(ctx, p.ChannelID, p.MessageID)
+		if errors.Is(err, poll.ErrCustomPollNotFound) {
+			continue
+		} else if err != nil {
+			return "", err
+		}
+
+		if len(postIDs) > 0 && !slices.Equal(c.Weights, cp.Weights) {
+			return "", MessageErr{CommandName: "results", Msg: "Copies of poll have different role weights, so their weighted votes can't be combined"}
+		}
+
+		t, err := db.FindTally(ctx, c.PostID, len(c.Answers))
+		if err != nil {
+			return "", err
+		}
+
+		if weighted == nil {
+			weighted = make([]int, len(c.Answers))
+		}
+		for i, v := range t.Weighted {
+			if i < len(weighted) {
+				weighted[i] += v
+			}
+		}
+
+		cp = c
+		postIDs = append(postIDs, p.ID)
+	}
+
+	if len(postIDs) == 0 {
+		return "", nil
+	}
+
+	var summary []string
+	if len(cp.Weights) > 0 {
+		summary = append(summary, weightedSummary(cp.Answers, weighted, cp.Weights))
+	}
+
+	if cp.Method == poll.Ranked {
+		ballots, err := db.FindBallots(ctx, postIDs)
+		if err != nil {
+			return "", err
+		}
+
+		summary = append(summary, runoffSummary(cp.Answers, poll.InstantRunoff(len(cp.Answers), ballots)))
+	}
+
+	return strings.Join(summary, "\n"), nil
+}
+
+func weightedSummary(answers []poll.Answer, weighted []int, weights []poll.RoleWeight) string {
+	votes := make([]string, 0, len(answers))
+	for i, a := range answers {
+		if i < len(weighted) {
+			votes = append(votes, fmt.Sprintf("%s %d", a.Text, weighted[i]))
+		}
+	}
+
+	return fmt.Sprintf("**Weighted votes** (%s): %s", poll.FormatRoleWeights(weights), strings.Join(votes, ", "))
+}
+
+func weightOptions() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "weights",
+			Description: "Weights of roles in poll posted with buttons engine, e.g. @Maintainers=2, @Core=3",
+		},
+	}
+}
@@ -0,0 +1,139 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// MaxHeartbeatAge is the longest time without heartbeat ACK from Discord, after that gateway is treated as dead.
+// Discord sends heartbeat interval around 41 seconds
+const MaxHeartbeatAge = 90 * time.Second
+
+const pingTimeout = 2 * time.Second
+
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+type Checker struct {
+	Session *discordgo.Session
+	Db      Pinger
+
+	connected   atomic.Bool
+	connects    atomic.Uint64
+	disconnects atomic.Uint64
+}
+
+// AddHandlers registers gateway handlers, which track connection state
+func (c *Checker) AddHandlers(s *discordgo.Session) {
+	s.AddHandler(func(_ *discordgo.Session, _ *discordgo.Connect) {
+		c.connected.Store(true)
+		c.connects.Add(1)
+	})
+	s.AddHandler(func(_ *discordgo.Session, _ *discordgo.Disconnect) {
+		c.connected.Store(false)
+		c.disconnects.Add(1)
+	})
+}
+
+// Reconnects returns number of gateway connections opened after the first one
+func (c *Checker) Reconnects() uint64 {
+	if n := c.connects.Load(); n > 0 {
+		return n - 1
+	}
+
+	return 0
+}
+
+type gatewayStatus struct {
+	Connected        bool      `json:"connected"`
+	Heartbeating     bool      `json:"heartbeating"`
+	Latency          string    `json:"latency"`
+	LastHeartbeatAck time.Time `json:"lastHeartbeatAck"`
+	Reconnects       uint64    `json:"reconnects"`
+	Disconnects      uint64    `json:"disconnects"`
+}
+
+type databaseStatus struct {
+	Ok    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+type readyStatus struct {
+	Ready    bool           `json:"ready"`
+	Gateway  gatewayStatus  `json:"gateway"`
+	Database databaseStatus `json:"database"`
+}
+
+func (c *Checker) gatewayStatus(now time.Time) gatewayStatus {
+	status := gatewayStatus{
+		Connected:   c.connected.Load(),
+		Reconnects:  c.Reconnects(),
+		Disconnects: c.disconnects.Load(),
+	}
+
+	if c.Session != nil {
+		c.Session.RLock()
+		status.LastHeartbeatAck = c.Session.LastHeartbeatAck
+		status.Latency = c.Session.HeartbeatLatency().String()
+		c.Session.RUnlock()
+	}
+
+	status.Heartbeating = !status.LastHeartbeatAck.IsZero() && now.Sub(status.LastHeartbeatAck) <= MaxHeartbeatAge
+
+	return status
+}
+
+func (c *Checker) databaseStatus(ctx context.Context) (status databaseStatus) {
+	if c.Db == nil {
+		status.Error = "database isn't configured"
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	if err := c.Db.Ping(ctx); err != nil {
+		status.Error = err.Error()
+		return
+	}
+
+	status.Ok = true
+	return
+}
+
+// Healthz responses always with 200 until process is able to handle HTTP requests
+func (c *Checker) Healthz(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Readyz checks if gateway is connected and heartbeating and database is reachable
+func (c *Checker) Readyz(w http.ResponseWriter, r *http.Request) {
+	status := readyStatus{
+		Gateway:  c.gatewayStatus(time.Now()),
+		Database: c.databaseStatus(r.Context()),
+	}
+	status.Ready = status.Gateway.Connected && status.Gateway.Heartbeating && status.Database.Ok
+
+	code := http.StatusOK
+	if !status.Ready {
+		code = http.StatusServiceUnavailable
+	}
+
+	writeJSON(w, code, status)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Warn("failed write health response", "error", err)
+	}
+}
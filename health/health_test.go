@@ -0,0 +1,65 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+type pingerFunc func(ctx context.Context) error
+
+func (f pingerFunc) Ping(ctx context.Context) error {
+	return f(ctx)
+}
+
+func TestReadyz(t *testing.T) {
+	okPing := pingerFunc(func(context.Context) error { return nil })
+	failedPing := pingerFunc(func(context.Context) error { return errors.New("connection refused") })
+
+	data := map[string]struct {
+		connected bool
+		ack       time.Time
+		db        Pinger
+		exp       int
+	}{
+		"ready":            {true, time.Now(), okPing, http.StatusOK},
+		"disconnected":     {false, time.Now(), okPing, http.StatusServiceUnavailable},
+		"heartbeat missed": {true, time.Now().Add(-2 * MaxHeartbeatAge), okPing, http.StatusServiceUnavailable},
+		"database down":    {true, time.Now(), failedPing, http.StatusServiceUnavailable},
+		"missing database": {true, time.Now(), nil, http.StatusServiceUnavailable},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			c := Checker{
+				Session: &discordgo.Session{LastHeartbeatAck: d.ack, LastHeartbeatSent: d.ack},
+				Db:      d.db,
+			}
+			c.connected.Store(d.connected)
+
+			rec := httptest.NewRecorder()
+			c.Readyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if rec.Code != d.exp {
+				t.Fatalf("invalid status code. Expected: %d, got: %d, body: %s", d.exp, rec.Code, rec.Body)
+			}
+		})
+	}
+}
+
+func TestReconnects(t *testing.T) {
+	var c Checker
+	if got := c.Reconnects(); got != 0 {
+		t.Fatalf("invalid reconnects before first connection. Expected: 0, got: %d", got)
+	}
+
+	c.connects.Add(3)
+	if got := c.Reconnects(); got != 2 {
+		t.Fatalf("invalid reconnects. Expected: 2, got: %d", got)
+	}
+}
@@ -0,0 +1,114 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+var ErrStopping = errors.New("lifecycle: manager is stopping")
+
+// Manager tracks in-flight work and owns root context, from which all handlers derive their contexts
+type Manager struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	// stopCtx is canceled as soon as shutdown starts. Background loops started by Go stop on it
+	stopCtx context.Context
+	stop    context.CancelFunc
+
+	m        sync.RWMutex
+	wg       sync.WaitGroup
+	stopping bool
+}
+
+func New() *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopCtx, stop := context.WithCancel(ctx)
+
+	return &Manager{ctx: ctx, cancel: cancel, stopCtx: stopCtx, stop: stop}
+}
+
+// Context returns root context. It's canceled after in-flight work is finished or shutdown deadline is exceeded
+func (m *Manager) Context() context.Context {
+	return m.ctx
+}
+
+// Acquire registers new in-flight work. Returned function must be called after work is done
+func (m *Manager) Acquire() (func(), error) {
+	m.m.RLock()
+	defer m.m.RUnlock()
+
+	if m.stopping {
+		return nil, ErrStopping
+	}
+
+	m.wg.Add(1)
+
+	return m.wg.Done, nil
+}
+
+// Go runs fn in new goroutine as tracked in-flight work. Context passed to fn is canceled as soon as shutdown starts
+func (m *Manager) Go(fn func(ctx context.Context)) error {
+	done, err := m.Acquire()
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		defer done()
+		fn(m.stopCtx)
+	}()
+
+	return nil
+}
+
+// WaitForSignal blocks until SIGINT or SIGTERM is received
+func (m *Manager) WaitForSignal() os.Signal {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(ch)
+
+	return <-ch
+}
+
+// Shutdown stops accepting new work and waits for in-flight work until ctx is done. Root context is canceled only
+// after the wait, so in-flight handlers are drained instead of interrupted
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.m.Lock()
+	m.stopping = true
+	m.m.Unlock()
+
+	m.stop()
+	defer m.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.Join(errors.New("lifecycle: in-flight work didn't finish before deadline"), ctx.Err())
+	}
+}
+
+// Handler adapts h to discordgo event handler. Events received during shutdown are dropped
+func Handler[T any](m *Manager, h func(ctx context.Context, s *discordgo.Session, event T)) func(*discordgo.Session, T) {
+	return func(s *discordgo.Session, event T) {
+		done, err := m.Acquire()
+		if err != nil {
+			return
+		}
+		defer done()
+
+		h(m.ctx, s, event)
+	}
+}
@@ -0,0 +1,96 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestShutdownWaitsForInFlightWork(t *testing.T) {
+	m := New()
+
+	var (
+		started  = make(chan struct{})
+		finished = make(chan struct{})
+	)
+	if err := m.Go(func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		close(finished)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := m.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-finished:
+	default:
+		t.Fatal("shutdown returned before in-flight work finished")
+	}
+
+	if _, err := m.Acquire(); !errors.Is(err, ErrStopping) {
+		t.Fatalf("new work accepted after shutdown. got error: %v", err)
+	}
+}
+
+func TestShutdownDrainsHandlers(t *testing.T) {
+	m := New()
+
+	done, err := m.Acquire()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	finished := make(chan error, 1)
+	go func() {
+		// Handler must still be able to finish its work after shutdown started
+		time.Sleep(10 * time.Millisecond)
+		finished <- m.Context().Err()
+		done()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := m.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-finished; err != nil {
+		t.Fatalf("root context canceled before in-flight work finished: %v", err)
+	}
+
+	if m.Context().Err() == nil {
+		t.Fatal("root context isn't canceled after shutdown")
+	}
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	m := New()
+
+	done, err := m.Acquire()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := m.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline error, got: %v", err)
+	}
+
+	if m.Context().Err() == nil {
+		t.Fatal("root context isn't canceled after deadline")
+	}
+}
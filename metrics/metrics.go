@@ -0,0 +1,295 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are latency buckets in seconds, same as Prometheus client defaults
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type collector interface {
+	write(w io.Writer)
+}
+
+type Registry struct {
+	m          sync.Mutex
+	collectors []collector
+	names      map[string]struct{}
+}
+
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]struct{})}
+}
+
+var defaultRegistry = NewRegistry()
+
+func (r *Registry) register(name string, c collector) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	if _, ok := r.names[name]; ok {
+		panic(fmt.Sprintf("metrics: duplicated metric %q", name))
+	}
+
+	r.names[name] = struct{}{}
+	r.collectors = append(r.collectors, c)
+}
+
+// Write writes all registered metrics in Prometheus text exposition format
+func (r *Registry) Write(w io.Writer) {
+	r.m.Lock()
+	collectors := slices.Clone(r.collectors)
+	r.m.Unlock()
+
+	for _, c := range collectors {
+		c.write(w)
+	}
+}
+
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	r.Write(w)
+}
+
+func Handler() http.Handler {
+	return defaultRegistry
+}
+
+type desc struct {
+	name   string
+	help   string
+	labels []string
+}
+
+func (d desc) writeHeader(w io.Writer, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.name, escapeHelp(d.help), d.name, kind)
+}
+
+func (d desc) key(values []string) string {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d labels, got %d", d.name, len(d.labels), len(values)))
+	}
+
+	return strings.Join(values, "\xff")
+}
+
+func (d desc) labelPairs(values []string, extra ...string) string {
+	pairs := make([]string, 0, len(values)+len(extra)/2)
+	for i, v := range values {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, d.labels[i], labelReplacer.Replace(v)))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extra[i], labelReplacer.Replace(extra[i+1])))
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+type CounterVec struct {
+	desc
+	m      sync.Mutex
+	values map[string]*counterValue
+}
+
+type counterValue struct {
+	labels []string
+	value  float64
+}
+
+func NewCounter(name, help string, labels ...string) *CounterVec {
+	return defaultRegistry.NewCounter(name, help, labels...)
+}
+
+func (r *Registry) NewCounter(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{
+		desc:   desc{name: name, help: help, labels: labels},
+		values: make(map[string]*counterValue),
+	}
+	r.register(name, c)
+
+	return c
+}
+
+func (c *CounterVec) Inc(labels ...string) {
+	c.Add(1, labels...)
+}
+
+func (c *CounterVec) Add(v float64, labels ...string) {
+	if v < 0 {
+		panic("metrics: counter cannot decrease")
+	}
+
+	key := c.key(labels)
+
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	val, ok := c.values[key]
+	if !ok {
+		val = &counterValue{labels: slices.Clone(labels)}
+		c.values[key] = val
+	}
+	val.value += v
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	c.writeHeader(w, "counter")
+
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	for _, key := range sortedKeys(c.values) {
+		val := c.values[key]
+		fmt.Fprintf(w, "%s%s %s\n", c.name, c.labelPairs(val.labels), formatFloat(val.value))
+	}
+}
+
+type HistogramVec struct {
+	desc
+	buckets []float64
+	m       sync.Mutex
+	values  map[string]*histogramValue
+}
+
+type histogramValue struct {
+	labels []string
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func NewHistogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	return defaultRegistry.NewHistogram(name, help, buckets, labels...)
+}
+
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	h := &HistogramVec{
+		desc:    desc{name: name, help: help, labels: labels},
+		buckets: slices.Sorted(slices.Values(buckets)),
+		values:  make(map[string]*histogramValue),
+	}
+	r.register(name, h)
+
+	return h
+}
+
+func (h *HistogramVec) Observe(v float64, labels ...string) {
+	key := h.key(labels)
+
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	val, ok := h.values[key]
+	if !ok {
+		val = &histogramValue{labels: slices.Clone(labels), counts: make([]uint64, len(h.buckets))}
+		h.values[key] = val
+	}
+
+	for i, b := range h.buckets {
+		if v <= b {
+			val.counts[i]++
+		}
+	}
+	val.count++
+	val.sum += v
+}
+
+// ObserveSince records time elapsed since start in seconds
+func (h *HistogramVec) ObserveSince(start time.Time, labels ...string) {
+	h.Observe(time.Since(start).Seconds(), labels...)
+}
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.writeHeader(w, "histogram")
+
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	for _, key := range sortedKeys(h.values) {
+		val := h.values[key]
+		for i, b := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelPairs(val.labels, "le", formatFloat(b)), val.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelPairs(val.labels, "le", "+Inf"), val.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, h.labelPairs(val.labels), formatFloat(val.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, h.labelPairs(val.labels), val.count)
+	}
+}
+
+type GaugeFunc struct {
+	desc
+	kind string
+	fn   func() float64
+}
+
+// NewGaugeFunc registers gauge, which value is read from fn on every scrape
+func NewGaugeFunc(name, help string, fn func() float64) *GaugeFunc {
+	return defaultRegistry.newFunc(name, help, "gauge", fn)
+}
+
+// NewCounterFunc registers counter, which value is read from fn on every scrape.
+// fn must return monotonically increasing values
+func NewCounterFunc(name, help string, fn func() float64) *GaugeFunc {
+	return defaultRegistry.newFunc(name, help, "counter", fn)
+}
+
+func (r *Registry) newFunc(name, help, kind string, fn func() float64) *GaugeFunc {
+	g := &GaugeFunc{desc: desc{name: name, help: help}, kind: kind, fn: fn}
+	r.register(name, g)
+
+	return g
+}
+
+func (g *GaugeFunc) write(w io.Writer) {
+	g.writeHeader(w, g.kind)
+	fmt.Fprintf(w, "%s %s\n", g.name, formatFloat(g.fn()))
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+var (
+	helpReplacer  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpReplacer.Replace(s)
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	r := NewRegistry()
+
+	c := r.NewCounter("test_total", "Test counter", "command")
+	c.Inc("poll")
+	c.Add(2, "poll")
+	c.Inc(`say "hi"`)
+
+	h := r.NewHistogram("test_seconds", "Test histogram", []float64{1, 0.5})
+	h.Observe(0.3)
+	h.Observe(0.7)
+	h.Observe(3)
+
+	r.newFunc("test_gauge", "Test\ngauge", "gauge", func() float64 { return 7 })
+
+	var buf bytes.Buffer
+	r.Write(&buf)
+
+	exp := strings.Join([]string{
+		"# HELP test_total Test counter",
+		"# TYPE test_total counter",
+		`test_total{command="poll"} 3`,
+		`test_total{command="say \"hi\""} 1`,
+		"# HELP test_seconds Test histogram",
+		"# TYPE test_seconds histogram",
+		`test_seconds_bucket{le="0.5"} 1`,
+		`test_seconds_bucket{le="1"} 2`,
+		`test_seconds_bucket{le="+Inf"} 3`,
+		"test_seconds_sum 4",
+		"test_seconds_count 3",
+		`# HELP test_gauge Test\ngauge`,
+		"# TYPE test_gauge gauge",
+		"test_gauge 7",
+		"",
+	}, "\n")
+
+	if got := buf.String(); got != exp {
+		t.Fatalf("invalid metrics output. Expected:\n%s\ngot:\n%s", exp, got)
+	}
+}
+
+func TestRegistryDuplicatedName(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for duplicated metric name")
+		}
+	}()
+
+	r := NewRegistry()
+	r.NewCounter("test_total", "")
+	r.NewCounter("test_total", "")
+}
@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	SlashCommands = NewCounter(
+		"yomoid_slash_commands_total",
+		"Number of handled slash commands",
+		"command", "subcommand",
+	)
+	SlashCommandDuration = NewHistogram(
+		"yomoid_slash_command_duration_seconds",
+		"Time spent on handling slash command",
+		nil,
+		"command", "subcommand",
+	)
+	Errors = NewCounter(
+		"yomoid_errors_total",
+		"Number of errors returned by handlers",
+		"type",
+	)
+	NinegagLinksFixed = NewCounter(
+		"yomoid_ninegag_links_fixed_total",
+		"Number of fixed 9gag links",
+	)
+	PollsCaptured = NewCounter(
+		"yomoid_polls_captured_total",
+		"Number of native polls saved by message create handler",
+	)
+	PollsPurged = NewCounter(
+		"yomoid_polls_purged_total",
+		"Number of soft-deleted polls permanently removed after retention window",
+	)
+	ImageMainColorDuration = NewHistogram(
+		"yomoid_image_main_color_duration_seconds",
+		"Time spent on searching main color of user avatar",
+		nil,
+	)
+	DiscordRESTFailures = NewCounter(
+		"yomoid_discord_rest_failures_total",
+		"Number of failed requests to Discord REST API",
+		"method", "status",
+	)
+)
+
+const (
+	ErrorTypeUser     = "user"
+	ErrorTypeTimeout  = "timeout"
+	ErrorTypeInternal = "internal"
+)
+
+// RegisterPoolStats exposes pgxpool statistics. It should be called only once
+func RegisterPoolStats(stat func() *pgxpool.Stat) {
+	NewGaugeFunc("yomoid_db_pool_acquired_connections", "Number of currently acquired connections", func() float64 {
+		return float64(stat().AcquiredConns())
+	})
+	NewGaugeFunc("yomoid_db_pool_idle_connections", "Number of currently idle connections", func() float64 {
+		return float64(stat().IdleConns())
+	})
+	NewGaugeFunc("yomoid_db_pool_total_connections", "Total number of connections in pool", func() float64 {
+		return float64(stat().TotalConns())
+	})
+	NewGaugeFunc("yomoid_db_pool_max_connections", "Maximum size of pool", func() float64 {
+		return float64(stat().MaxConns())
+	})
+	NewCounterFunc("yomoid_db_pool_acquires_total", "Number of successful acquires from pool", func() float64 {
+		return float64(stat().AcquireCount())
+	})
+	NewCounterFunc("yomoid_db_pool_empty_acquires_total", "Number of acquires, which waited for connection", func() float64 {
+		return float64(stat().EmptyAcquireCount())
+	})
+	NewCounterFunc("yomoid_db_pool_canceled_acquires_total", "Number of acquires canceled by context", func() float64 {
+		return float64(stat().CanceledAcquireCount())
+	})
+	NewCounterFunc("yomoid_db_pool_acquire_duration_seconds_total", "Total time spent on acquiring connections", func() float64 {
+		return stat().AcquireDuration().Seconds()
+	})
+}
+
+// Transport counts failed requests. Discord responses with status code 400 or above are counted as failures too
+type Transport struct {
+	Base http.RoundTripper
+}
+
+func (t Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	res, err := base.RoundTrip(req)
+	if err != nil {
+		DiscordRESTFailures.Inc(req.Method, "error")
+	} else if res.StatusCode >= http.StatusBadRequest {
+		DiscordRESTFailures.Inc(req.Method, strconv.Itoa(res.StatusCode))
+	}
+
+	return res, err
+}
@@ -3,7 +3,6 @@ package yomoid
 import (
 	"database/sql"
 	"embed"
-	"os"
 
 	"github.com/pressly/goose/v3"
 	"github.com/wittano/yomoid/logger"
@@ -21,13 +20,6 @@ func MigrateDatabase(dbURL string) (err error) {
 	}
 	defer logger.LogCloser(db)
 
-	if os.Getenv("GOOSE_DBSTRING") == "" {
-		err = os.Setenv("GOOSE_DBSTRING", dbURL)
-	}
-	if err != nil {
-		return err
-	}
-
 	goose.SetBaseFS(migrations)
 	if err := goose.SetDialect("postgres"); err != nil {
 		return err
@@ -4,6 +4,10 @@ import (
 	"context"
 	"github.com/bwmarrin/discordgo"
 	"github.com/wittano/yomoid/logger"
+	"github.com/wittano/yomoid/metrics"
+	"github.com/wittano/yomoid/settings"
+	"github.com/wittano/yomoid/tracing"
+	"log/slog"
 	"regexp"
 	"strings"
 )
@@ -13,10 +17,27 @@ var (
 	fixNineGagRegex = regexp.MustCompile(`_460sv([a-z0-9]{3})`)
 )
 
-func MessageFixer(s *discordgo.Session, m *discordgo.MessageCreate) {
-	ctx, cancel := context.WithCancel(context.Background())
+type MessageFixer struct {
+	Settings *settings.Service
+}
+
+func (f MessageFixer) Handler(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate) {
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	ctx, span := tracing.StartEvent(ctx, "event MESSAGE_CREATE 9gag", m.GuildID, m.ChannelID)
+	defer span.End()
+
+	guildSettings, err := f.Settings.Get(ctx, m.GuildID)
+	if err != nil {
+		slog.WarnContext(ctx, "failed fetch guild settings. Default settings are used", "guildID", m.GuildID, "error", err)
+		tracing.RecordError(span, err)
+	}
+
+	if !guildSettings.LinkFixing {
+		return
+	}
+
 	l := logger.CreateLoggerFromMessage(ctx, s, *m.Message)
 
 	words := strings.Split(m.Message.Content, " ")
@@ -31,6 +52,7 @@ func MessageFixer(s *discordgo.Session, m *discordgo.MessageCreate) {
 		words[i], fixed = fixNinegagLink(link)
 		if fixed {
 			sendMessage = true
+			metrics.NinegagLinksFixed.Inc()
 		}
 	}
 
@@ -45,6 +67,7 @@ func MessageFixer(s *discordgo.Session, m *discordgo.MessageCreate) {
 		}, discordgo.WithContext(ctx))
 		if err != nil {
 			l.Error("failed send 9gag fixed links message", "error", err)
+			tracing.RecordError(span, err)
 		}
 	}
 }
@@ -0,0 +1,37 @@
+package poll
+
+import (
+	"context"
+
+	"github.com/wittano/yomoid/gen/database"
+)
+
+type AuditAction string
+
+const (
+	AuditCreated  AuditAction = "created"
+	AuditEdited   AuditAction = "edited"
+	AuditPosted   AuditAction = "posted"
+	AuditDeleted  AuditAction = "deleted"
+	AuditRestored AuditAction = "restored"
+)
+
+// AuditEntry records who and what did with poll template
+type AuditEntry struct {
+	PollID  int64
+	GuildID string
+	ActorID string
+	Action  AuditAction
+	// Details are settings, which are needed to check outcome later, e.g. weights of roles in posted poll
+	Details string
+}
+
+func audit(ctx context.Context, q *database.Queries, entry AuditEntry) error {
+	return q.CreatePollAudit(ctx, database.CreatePollAuditParams{
+		PollID:  entry.PollID,
+		GuildID: entry.GuildID,
+		ActorID: entry.ActorID,
+		Action:  string(entry.Action),
+		Details: ParseString(entry.Details),
+	})
+}
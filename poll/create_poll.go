@@ -5,22 +5,48 @@ import (
 	"fmt"
 	"github.com/bwmarrin/discordgo"
 	"github.com/wittano/yomoid/logger"
+	"github.com/wittano/yomoid/metrics"
+	"github.com/wittano/yomoid/settings"
+	"github.com/wittano/yomoid/tracing"
+	"log/slog"
 	"math"
 	"time"
 )
 
+// capturedPollReaction is added to captured poll in quiet mode
+const capturedPollReaction = "💾"
+
 type MessageCreateHandler struct {
-	Db Queries
+	Db       Queries
+	Settings *settings.Service
 }
 
-func (p MessageCreateHandler) Handler(s *discordgo.Session, m *discordgo.MessageCreate) {
+func (p MessageCreateHandler) Handler(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate) {
 	if m.Poll == nil || m.Poll.Question.Text == "" || len(m.Poll.Answers) == 0 {
 		return
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	ctx, span := tracing.StartEvent(ctx, "event MESSAGE_CREATE poll", m.GuildID, m.ChannelID)
+	defer span.End()
+
+	guildSettings, err := p.Settings.Get(ctx, m.GuildID)
+	if err != nil {
+		slog.WarnContext(ctx, "failed fetch guild settings. Default settings are used", "guildID", m.GuildID, "error", err)
+		tracing.RecordError(span, err)
+	}
+
+	var roles []string
+	if m.Member != nil {
+		roles = m.Member.Roles
+	}
+
+	if !guildSettings.Captures(m.ChannelID, roles) {
+		return
+	}
+
 	if p.Db.Exists(ctx, m.Poll.Question.Text, m.GuildID) {
 		return
 	}
@@ -30,13 +56,23 @@ func (p MessageCreateHandler) Handler(s *discordgo.Session, m *discordgo.Message
 
 	l.InfoContext(ctx, "PollMessageCreate handler received a new poll")
 
-	pollId, err := p.Db.CreatePoll(ctx, createPoll(*m))
+	pollId, err := p.Db.CreatePoll(ctx, NewCreatePollParams(*m.Message, guildSettings.DefaultDuration))
 	if err != nil {
 		l.Error("failed create a new poll", "error", err)
+		tracing.RecordError(span, err)
 		return
 	}
 
 	l.Info("poll created a new poll", "pollId", pollId)
+	metrics.PollsCaptured.Inc()
+
+	if guildSettings.CaptureQuiet {
+		if err = s.MessageReactionAdd(m.ChannelID, m.ID, capturedPollReaction, discordgo.WithContext(ctx)); err != nil {
+			l.ErrorContext(ctx, "failed add reaction to captured poll", "error", err)
+			tracing.RecordError(span, err)
+		}
+		return
+	}
 
 	if _, err = s.ChannelMessageSendComplex(m.ChannelID, &discordgo.MessageSend{
 		Flags: discordgo.MessageFlagsEphemeral,
@@ -47,30 +83,46 @@ func (p MessageCreateHandler) Handler(s *discordgo.Session, m *discordgo.Message
 			ChannelID: m.ChannelID,
 		},
 		Content: fmt.Sprintf("I saved your poll %s. Model's id is `%d`", m.Poll.Question.Text, pollId),
-	}); err != nil {
+	}, discordgo.WithContext(ctx)); err != nil {
 		l.ErrorContext(ctx, "failed response for creating a new poll in database", "error", err)
+		tracing.RecordError(span, err)
 	}
 }
 
-func createPoll(msg discordgo.MessageCreate) (poll CreatePollParams) {
-	duration := math.Ceil(time.Until(*msg.Poll.Expiry).Hours())
-	if duration == 0 {
-		duration = 24
+// Durations are poll durations in hours supported by Discord
+var Durations = []int16{1, 4, 8, 24, 72, 168, 336}
+
+// NewCreatePollParams maps native poll from message into template. Duration is rounded up to the closest duration
+// supported by Discord. Expired poll or poll without expiry gets defaultDuration
+func NewCreatePollParams(msg discordgo.Message, defaultDuration int16) (poll CreatePollParams) {
+	duration := defaultDuration
+	if msg.Poll.Expiry != nil {
+		if hours := math.Ceil(time.Until(*msg.Poll.Expiry).Hours()); hours > 0 {
+			duration = roundDuration(hours)
+		}
 	}
 
 	poll.GuildID = msg.GuildID
-	poll.Duration = int16(duration)
+	poll.Duration = duration
 	poll.IsMulti = msg.Poll.AllowMultiselect
 	poll.AuthorID = msg.Author.ID
 	poll.Question = msg.Poll.Question.Text
 	poll.Answers = make([]AnswerParams, len(msg.Poll.Answers))
 
 	for i, a := range msg.Poll.Answers {
-		if a.Media.Emoji != nil {
-			poll.Answers[i].Emoji = a.Media.Emoji.Name
-		}
+		poll.Answers[i].Emoji = NewEmoji(a.Media.Emoji)
 		poll.Answers[i].Text = a.Media.Text
 	}
 
 	return
 }
+
+func roundDuration(hours float64) int16 {
+	for _, d := range Durations {
+		if float64(d) >= hours {
+			return d
+		}
+	}
+
+	return Durations[len(Durations)-1]
+}
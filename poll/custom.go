@@ -0,0 +1,369 @@
+package poll
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/wittano/yomoid/gen/database"
+)
+
+// Engine renders posted poll
+type Engine string
+
+const (
+	// EngineNative posts Discord's native poll
+	EngineNative Engine = "native"
+	// EngineButtons posts embed with buttons or select menu. Votes are stored in database
+	EngineButtons Engine = "buttons"
+)
+
+func (e Engine) Valid() bool {
+	return e == EngineNative || e == EngineButtons
+}
+
+// MaxCustomAnswers is limit of answers of poll posted with buttons engine. It's limit of options in select menu
+const MaxCustomAnswers = 25
+
+var ErrCustomPollNotFound = errors.New("database: custom poll not found")
+
+// Answer is answer of template or rendered answer of posted poll
+type Answer struct {
+	Text  string
+	Emoji Emoji
+}
+
+func (a Answer) String() string {
+	if a.Emoji.IsZero() {
+		return a.Text
+	}
+
+	return a.Emoji.String() + " " + a.Text
+}
+
+// CustomPoll is poll posted with buttons engine. Answers are rendered snapshot of template
+type CustomPoll struct {
+	PostID    int64
+	PollID    int64
+	GuildID   string
+	ChannelID string
+	MessageID string
+	Question  string
+	Answers   []Answer
+	IsMulti   bool
+	Method    VotingMethod
+	// Anonymous hides voters from other members
+	Anonymous bool
+	// VoterRoleID restricts voting to members with role. Empty means everyone can vote
+	VoterRoleID string
+	ClosesAt    time.Time
+	Closed      bool
+	// Weights multiply votes of role members. Empty means every vote counts once
+	Weights []RoleWeight
+	// AllowOther lets voters type free-text answer
+	AllowOther bool
+	// PostedBy is user, who posted poll
+	PostedBy string
+}
+
+// IsOpen checks if members can vote at now
+func (p CustomPoll) IsOpen(now time.Time) bool {
+	return !p.Closed && now.Before(p.ClosesAt)
+}
+
+// Tally are votes of custom poll
+type Tally struct {
+	// Votes is number of votes per answer index
+	Votes []int
+	// Voters are user IDs, who voted for answer with index
+	Voters [][]string
+	// Total is number of unique voters
+	Total int
+	// Weighted are votes per answer index multiplied by voter's weight
+	Weighted []int
+	// WeightedTotal is sum of weights of unique voters
+	WeightedTotal int
+}
+
+func newTally(answers int, votes []database.FindCustomPollVotesRow) Tally {
+	t := Tally{Votes: make([]int, answers), Voters: make([][]string, answers), Weighted: make([]int, answers)}
+
+	var users []string
+	for _, v := range votes {
+		// Ranked ballots are counted by the most preferred answer
+		if int(v.Answer) >= answers || v.Rank > 0 {
+			continue
+		}
+
+		t.Votes[v.Answer]++
+		t.Weighted[v.Answer] += int(v.Weight)
+		t.Voters[v.Answer] = append(t.Voters[v.Answer], v.UserID)
+		if !slices.Contains(users, v.UserID) {
+			users = append(users, v.UserID)
+			t.WeightedTotal += int(v.Weight)
+		}
+	}
+	t.Total = len(users)
+
+	return t
+}
+
+func newCustomPoll(r database.FindCustomPollByMessageRow) CustomPoll {
+	answers := make([]Answer, len(r.Answers))
+	for i, a := range r.Answers {
+		answers[i].Text = a
+		if i < len(r.Emojis) {
+			answers[i].Emoji = ParseEmoji(r.Emojis[i])
+		}
+	}
+
+	return CustomPoll{
+		PostID:      r.PostID,
+		PollID:      r.PollID,
+		GuildID:     r.GuildID,
+		ChannelID:   r.ChannelID,
+		MessageID:   r.MessageID,
+		Question:    r.Question,
+		Answers:     answers,
+		IsMulti:     r.IsMulti,
+		Method:      newVotingMethod(r.VotingMethod),
+		Anonymous:   r.Anonymous,
+		VoterRoleID: r.VoterRoleID.String,
+		ClosesAt:    r.ClosesAt.Time,
+		Closed:      r.ClosedAt.Valid,
+		AllowOther:  r.AllowOther,
+		PostedBy:    r.PostedBy,
+	}
+}
+
+func (d Database) CreateCustomPoll(ctx context.Context, p CustomPoll) (err error) {
+	tx, err := d.poll.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, tx.Rollback(ctx))
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	answers := make([]string, len(p.Answers))
+	emojis := make([]string, len(p.Answers))
+	for i, a := range p.Answers {
+		answers[i] = a.Text
+		emojis[i] = a.Emoji.String()
+	}
+
+	q := database.New(tx)
+	err = q.CreateCustomPoll(ctx, database.CreateCustomPollParams{
+		PostID:       p.PostID,
+		Question:     p.Question,
+		Answers:      answers,
+		Emojis:       emojis,
+		IsMulti:      p.IsMulti,
+		Anonymous:    p.Anonymous,
+		VoterRoleID:  ParseString(p.VoterRoleID),
+		ClosesAt:     pgtype.Timestamptz{Time: p.ClosesAt, Valid: true},
+		VotingMethod: string(newVotingMethod(string(p.Method))),
+		AllowOther:   p.AllowOther,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, w := range p.Weights {
+		err = q.CreateCustomPollWeight(ctx, database.CreateCustomPollWeightParams{PostID: p.PostID, RoleID: w.RoleID, Weight: w.Weight})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func findWeights(ctx context.Context, q *database.Queries, postID int64) ([]RoleWeight, error) {
+	data, err := q.FindCustomPollWeights(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	weights := make([]RoleWeight, len(data))
+	for i, w := range data {
+		weights[i] = RoleWeight{RoleID: w.RoleID, Weight: w.Weight}
+	}
+
+	return weights, nil
+}
+
+// FindCustomPoll returns poll posted in message with buttons engine
+func (d Database) FindCustomPoll(ctx context.Context, channelID, messageID string) (CustomPoll, error) {
+	q := database.New(d.poll)
+	r, err := q.FindCustomPollByMessage(ctx, database.FindCustomPollByMessageParams{ChannelID: channelID, MessageID: messageID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return CustomPoll{}, ErrCustomPollNotFound
+	} else if err != nil {
+		return CustomPoll{}, err
+	}
+
+	p := newCustomPoll(r)
+	p.Weights, err = findWeights(ctx, q, p.PostID)
+
+	return p, err
+}
+
+// FindCustomPollsToClose returns open custom polls, which should close before now
+func (d Database) FindCustomPollsToClose(ctx context.Context, now time.Time) ([]CustomPoll, error) {
+	q := database.New(d.poll)
+	data, err := q.FindCustomPollsToClose(ctx, pgtype.Timestamptz{Time: now, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	polls := make([]CustomPoll, len(data))
+	for i, r := range data {
+		polls[i] = newCustomPoll(database.FindCustomPollByMessageRow(r))
+		if polls[i].Weights, err = findWeights(ctx, q, polls[i].PostID); err != nil {
+			return nil, err
+		}
+	}
+
+	return polls, nil
+}
+
+func (d Database) CloseCustomPoll(ctx context.Context, postID int64) error {
+	return database.New(d.poll).CloseCustomPoll(ctx, postID)
+}
+
+// FailCustomPollClose counts failed closing of poll. It returns number of failures
+func (d Database) FailCustomPollClose(ctx context.Context, postID int64) (int16, error) {
+	return database.New(d.poll).FailCustomPollClose(ctx, postID)
+}
+
+// FindUserVotes returns answer indexes chosen by user
+func (d Database) FindUserVotes(ctx context.Context, postID int64, userID string) ([]int, error) {
+	data, err := database.New(d.poll).FindCustomPollUserVotes(ctx, database.FindCustomPollUserVotesParams{PostID: postID, UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	answers := make([]int, len(data))
+	for i, a := range data {
+		answers[i] = int(a)
+	}
+
+	return answers, nil
+}
+
+// VoteParams are all votes of user in custom poll
+type VoteParams struct {
+	PostID int64
+	UserID string
+	// Answers are indexes of chosen answers. Empty answers remove user's vote. Answers of ranked poll are ordered
+	// from the most preferred
+	Answers []int
+	Ranked  bool
+	// Weight is computed from user's roles. Zero means 1
+	Weight int16
+	// ClearReply removes user's free-text answer, when user votes for fixed answer. It's set for single choice polls
+	ClearReply bool
+	// Toggle computes Answers from current votes of user, e.g. by ToggleVote. Current votes are read in the same
+	// transaction, so concurrent clicks of user don't overwrite each other
+	Toggle func(current []int) []int
+}
+
+// SetVotes replaces votes of user. It returns saved answers. Votes of poll are changed one by one, so the same user
+// can't save votes concurrently
+func (d Database) SetVotes(ctx context.Context, params VoteParams) (_ []int, err error) {
+	tx, err := d.poll.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, tx.Rollback(ctx))
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	q := database.New(tx)
+	// User without votes doesn't have rows to lock, so poll is locked instead
+	if err = q.LockCustomPoll(ctx, params.PostID); err != nil {
+		return nil, err
+	}
+
+	if params.Toggle != nil {
+		current, err := q.FindCustomPollUserVotes(ctx, database.FindCustomPollUserVotesParams{PostID: params.PostID, UserID: params.UserID})
+		if err != nil {
+			return nil, err
+		}
+
+		answers := make([]int, len(current))
+		for i, a := range current {
+			answers[i] = int(a)
+		}
+		params.Answers = params.Toggle(answers)
+	}
+
+	if err = q.DeleteCustomPollUserVotes(ctx, database.DeleteCustomPollUserVotesParams{PostID: params.PostID, UserID: params.UserID}); err != nil {
+		return nil, err
+	}
+
+	if params.ClearReply && len(params.Answers) > 0 {
+		err = q.DeleteCustomPollReply(ctx, database.DeleteCustomPollReplyParams{PostID: params.PostID, UserID: params.UserID})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	weight := max(params.Weight, 1)
+	for rank, a := range params.Answers {
+		if !params.Ranked {
+			rank = 0
+		}
+
+		err = q.CreateCustomPollVote(ctx, database.CreateCustomPollVoteParams{
+			PostID: params.PostID,
+			UserID: params.UserID,
+			Answer: int16(a),
+			Rank:   int16(rank),
+			Weight: weight,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return params.Answers, nil
+}
+
+func (d Database) FindTally(ctx context.Context, postID int64, answers int) (Tally, error) {
+	data, err := database.New(d.poll).FindCustomPollVotes(ctx, postID)
+	if err != nil {
+		return Tally{}, err
+	}
+
+	return newTally(answers, data), nil
+}
+
+// ToggleVote computes user's votes after clicking answer. Clicking chosen answer removes it. In single choice poll
+// other answer replaces current vote
+func ToggleVote(current []int, answer int, multi bool) []int {
+	if slices.Contains(current, answer) {
+		return slices.DeleteFunc(slices.Clone(current), func(a int) bool { return a == answer })
+	}
+
+	if !multi {
+		return []int{answer}
+	}
+
+	votes := append(slices.Clone(current), answer)
+	slices.Sort(votes)
+
+	return votes
+}
@@ -0,0 +1,30 @@
+package poll
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestToggleVote(t *testing.T) {
+	tests := []struct {
+		name    string
+		current []int
+		answer  int
+		multi   bool
+		want    []int
+	}{
+		{"first vote", nil, 2, false, []int{2}},
+		{"change vote", []int{1}, 2, false, []int{2}},
+		{"remove vote", []int{2}, 2, false, []int{}},
+		{"add answer", []int{3}, 1, true, []int{1, 3}},
+		{"remove answer", []int{1, 3}, 3, true, []int{1}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ToggleVote(test.current, test.answer, test.multi); !slices.Equal(got, test.want) {
+				t.Fatalf("votes = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
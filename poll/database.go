@@ -4,31 +4,110 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/wittano/yomoid"
 	"github.com/wittano/yomoid/gen/database"
+	"github.com/wittano/yomoid/tracing"
 )
 
 type Model struct {
-	ID        int64
-	Question  string
-	GuildID   string
-	AuthorID  string
-	IsMulti   bool
-	Duration  int16
-	CreatedAt pgtype.Timestamptz
-	Options   []string
+	ID       int64
+	Question string
+	GuildID  string
+	AuthorID string
+	IsMulti  bool
+	Duration int16
+	Method   VotingMethod
+	// RevisionID is the latest revision loaded with template
+	RevisionID int64
+	CreatedAt  pgtype.Timestamptz
+	Answers    []Answer
+	Tags       []string
+	// Snippet is part of question or answers matched by full-text search
+	Snippet string
+}
+
+type SearchMode int
+
+const (
+	// SearchTitle matches polls containing title in question
+	SearchTitle SearchMode = iota
+	// SearchFullText ranks polls by full-text and trigram similarity of question and answers. It ignores accents and typos
+	SearchFullText
+)
+
+type SearchParams struct {
+	Title string
+	// Tag filters polls. Empty tag matches all polls
+	Tag  string
+	Mode SearchMode
+	Page uint
+}
+
+// fullText checks if polls are ranked by full-text search. Polls without title are listed by tag
+func (p SearchParams) fullText() bool {
+	return p.Mode == SearchFullText && strings.TrimSpace(p.Title) != ""
 }
 
 type Queries interface {
 	FindPoll(ctx context.Context, guildID string, id int64, title string) (Model, error)
-	FindAllPoll(ctx context.Context, guildID string, title string, page uint) ([]Model, error)
+	FindAllPoll(ctx context.Context, guildID string, params SearchParams) ([]Model, error)
 	CreatePoll(ctx context.Context, params CreatePollParams) (int64, error)
-	DeletePoll(ctx context.Context, id int64) error
+	// OverwritePoll replaces duration, multiselect and answers of poll with the same question in guild
+	OverwritePoll(ctx context.Context, params CreatePollParams) (int64, error)
+	// DeletePoll marks poll as deleted. Deleted poll can be restored until it's purged
+	DeletePoll(ctx context.Context, guildID string, id int64, actorID string) error
+	RestorePoll(ctx context.Context, guildID string, id int64, actorID string) error
+	// RecordPost links posted message with the latest revision of poll and records it in audit trail. It returns ID
+	// of post
+	RecordPost(ctx context.Context, params PostParams) (int64, error)
+	CountPosts(ctx context.Context, pollID int64) (int64, error)
+	FindLatestPosts(ctx context.Context, guildID string, pollID int64) ([]Post, error)
+	FindThreadsToArchive(ctx context.Context, now time.Time) ([]Post, error)
+	MarkThreadArchived(ctx context.Context, postID int64) error
+	FailThreadArchive(ctx context.Context, postID int64) (int16, error)
+	FindReminders(ctx context.Context, guildID string) ([]Reminder, error)
+	CancelReminder(ctx context.Context, guildID string, id int64) error
+	FindDueReminders(ctx context.Context, now time.Time) ([]Reminder, error)
+	FinishReminder(ctx context.Context, id int64, skipped bool) error
+	FailReminder(ctx context.Context, id int64) (int16, error)
+	RecordVote(ctx context.Context, channelID, messageID, userID string, answerID int) error
+	RemoveVote(ctx context.Context, channelID, messageID, userID string, answerID int) error
+	CountVoters(ctx context.Context, postID int64) (int64, error)
+	FindPostsToCheckQuorum(ctx context.Context, now time.Time) ([]Post, error)
+	FinishQuorum(ctx context.Context, postID int64, required int32, met *bool) error
+	FailQuorum(ctx context.Context, postID int64) (int16, error)
+	CreateCustomPoll(ctx context.Context, p CustomPoll) error
+	FindCustomPoll(ctx context.Context, channelID, messageID string) (CustomPoll, error)
+	FindCustomPollsToClose(ctx context.Context, now time.Time) ([]CustomPoll, error)
+	CloseCustomPoll(ctx context.Context, postID int64) error
+	FailCustomPollClose(ctx context.Context, postID int64) (int16, error)
+	FindUserVotes(ctx context.Context, postID int64, userID string) ([]int, error)
+	SetVotes(ctx context.Context, params VoteParams) ([]int, error)
+	SetVotingMethod(ctx context.Context, guildID string, id int64, method VotingMethod, actorID string) error
+	FindBallots(ctx context.Context, postIDs []int64) ([]Ballot, error)
+	SetReply(ctx context.Context, params ReplyParams) error
+	FindUserReply(ctx context.Context, postID int64, userID string) (string, error)
+	FindReplies(ctx context.Context, postID int64, page int) ([]Reply, int64, error)
+	FindTally(ctx context.Context, postID int64, answers int) (Tally, error)
+	AddTag(ctx context.Context, guildID string, pollID int64, tag string, weight int16) error
+	RemoveTag(ctx context.Context, guildID string, pollID int64, tag string) error
+	FindTags(ctx context.Context, guildID, prefix string) ([]string, error)
+	ExportPolls(ctx context.Context, guildID, tag string) (Export, error)
+	ImportPolls(ctx context.Context, guildID, actorID string, polls []ExportedPoll) ([]int64, error)
+	PickRandomPoll(ctx context.Context, guildID, tag string, since time.Time) (int64, error)
+	SaveSchedule(ctx context.Context, s Schedule) error
+	DeleteSchedule(ctx context.Context, guildID, channelID string) error
+	FindDueSchedules(ctx context.Context, now time.Time) ([]Schedule, error)
+	UpdateScheduleNextRun(ctx context.Context, id int64, next time.Time) error
+	FindRevisions(ctx context.Context, guildID string, pollID int64) ([]Revision, error)
+	RollbackPoll(ctx context.Context, guildID string, pollID int64, revision int32, actorID string) (Revision, error)
 	Exists(ctx context.Context, question, guildID string) bool
 }
 
@@ -42,7 +121,7 @@ func (d Database) Exists(ctx context.Context, question, guildID string) bool {
 	return result && err == nil
 }
 
-func (d Database) DeletePoll(ctx context.Context, id int64) error {
+func (d Database) DeletePoll(ctx context.Context, guildID string, id int64, actorID string) (err error) {
 	tx, err := d.poll.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return err
@@ -56,13 +135,54 @@ func (d Database) DeletePoll(ctx context.Context, id int64) error {
 	}()
 
 	q := database.New(tx)
-	return errors.Join(q.DeletePollOptions(ctx, id), q.DeletePoll(ctx, id))
+	n, err := q.SoftDeletePoll(ctx, database.SoftDeletePollParams{ID: id, GuildID: guildID})
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return ErrPollNotFound
+	}
 
+	return audit(ctx, q, AuditEntry{PollID: id, GuildID: guildID, ActorID: actorID, Action: AuditDeleted})
 }
 
-func (d Database) FindAllPoll(ctx context.Context, guildID string, title string, page uint) ([]Model, error) {
+func (d Database) RestorePoll(ctx context.Context, guildID string, id int64, actorID string) (err error) {
+	tx, err := d.poll.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, tx.Rollback(ctx))
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	q := database.New(tx)
+	n, err := q.RestorePoll(ctx, database.RestorePollParams{ID: id, GuildID: guildID})
+	if isUniqueViolation(err) {
+		return errors.Join(ErrPollExists, err)
+	} else if err != nil {
+		return err
+	} else if n == 0 {
+		return ErrPollNotFound
+	}
+
+	return audit(ctx, q, AuditEntry{PollID: id, GuildID: guildID, ActorID: actorID, Action: AuditRestored})
+}
+
+func (d Database) FindAllPoll(ctx context.Context, guildID string, params SearchParams) ([]Model, error) {
+	if params.fullText() {
+		return d.searchPoll(ctx, guildID, params)
+	}
+
 	q := database.New(d.poll)
-	data, err := q.FindPollByQuestion(ctx, database.FindPollByQuestionParams{Column1: title, GuildID: guildID, Offset: int32(page * 10)})
+	data, err := q.FindPollByQuestion(ctx, database.FindPollByQuestionParams{
+		Column1: params.Title,
+		GuildID: guildID,
+		Offset:  int32(params.Page * 10),
+		Column4: params.Tag,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -81,6 +201,25 @@ func (d Database) FindAllPoll(ctx context.Context, guildID string, title string,
 	return polls, nil
 }
 
+func (d Database) searchPoll(ctx context.Context, guildID string, params SearchParams) ([]Model, error) {
+	data, err := database.New(d.poll).SearchPoll(ctx, database.SearchPollParams{
+		GuildID:    guildID,
+		Tag:        params.Tag,
+		PageOffset: int32(params.Page * 10),
+		Search:     params.Title,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	polls := make([]Model, len(data))
+	for i, p := range data {
+		polls[i] = createPollData(database.FindPollByQuestionRow(p))
+	}
+
+	return polls, nil
+}
+
 var ErrPollNotFound = errors.New("database: poll not found")
 
 func (d Database) FindPoll(ctx context.Context, guildID string, id int64, title string) (poll Model, err error) {
@@ -91,14 +230,17 @@ func (d Database) FindPoll(ctx context.Context, guildID string, id int64, title
 			return
 		}
 		poll = Model{
-			ID:        p.ID,
-			Question:  p.Question,
-			GuildID:   p.GuildID,
-			AuthorID:  p.AuthorID,
-			IsMulti:   p.IsMulti,
-			Duration:  p.Duration,
-			CreatedAt: p.CreatedAt,
-			Options:   p.Options,
+			ID:         p.ID,
+			Question:   p.Question,
+			GuildID:    p.GuildID,
+			AuthorID:   p.AuthorID,
+			IsMulti:    p.IsMulti,
+			Duration:   p.Duration,
+			Method:     newVotingMethod(p.VotingMethod),
+			RevisionID: p.RevisionID,
+			CreatedAt:  p.CreatedAt,
+			Answers:    newAnswers(p.Answers, p.EmojiNames, p.EmojiIds, p.EmojiAnimated),
+			Tags:       p.Tags,
 		}
 	} else if id > 0 {
 		var p database.FindPollByIDRow
@@ -107,14 +249,17 @@ func (d Database) FindPoll(ctx context.Context, guildID string, id int64, title
 			return
 		}
 		poll = Model{
-			ID:        p.ID,
-			Question:  p.Question,
-			GuildID:   p.GuildID,
-			AuthorID:  p.AuthorID,
-			IsMulti:   p.IsMulti,
-			Duration:  p.Duration,
-			CreatedAt: p.CreatedAt,
-			Options:   p.Options,
+			ID:         p.ID,
+			Question:   p.Question,
+			GuildID:    p.GuildID,
+			AuthorID:   p.AuthorID,
+			IsMulti:    p.IsMulti,
+			Duration:   p.Duration,
+			Method:     newVotingMethod(p.VotingMethod),
+			RevisionID: p.RevisionID,
+			CreatedAt:  p.CreatedAt,
+			Answers:    newAnswers(p.Answers, p.EmojiNames, p.EmojiIds, p.EmojiAnimated),
+			Tags:       p.Tags,
 		}
 	} else if title != "" {
 		var p []database.FindPollByQuestionRow
@@ -135,32 +280,54 @@ func (d Database) FindPoll(ctx context.Context, guildID string, id int64, title
 
 func createPollData(p database.FindPollByQuestionRow) Model {
 	return Model{
-		ID:        p.ID,
-		Question:  p.Question,
-		GuildID:   p.GuildID,
-		AuthorID:  p.AuthorID,
-		IsMulti:   p.IsMulti,
-		Duration:  p.Duration,
-		CreatedAt: p.CreatedAt,
-		Options:   p.Options,
+		ID:         p.ID,
+		Question:   p.Question,
+		GuildID:    p.GuildID,
+		AuthorID:   p.AuthorID,
+		IsMulti:    p.IsMulti,
+		Duration:   p.Duration,
+		Method:     newVotingMethod(p.VotingMethod),
+		RevisionID: p.RevisionID,
+		CreatedAt:  p.CreatedAt,
+		Answers:    newAnswers(p.Answers, p.EmojiNames, p.EmojiIds, p.EmojiAnimated),
+		Tags:       p.Tags,
+		Snippet:    p.Snippet,
 	}
 }
 
 type AnswerParams struct {
 	Text  string
-	Emoji string
+	Emoji Emoji
+}
+
+func (a AnswerParams) String() string {
+	return Answer(a).String()
 }
 
 type CreatePollParams struct {
 	Question string
 	GuildID  string
 	AuthorID string
+	// ActorID is user, who saves poll. AuthorID is used if it's empty
+	ActorID  string
 	Duration int16
 	IsMulti  bool
 	Answers  []AnswerParams
 }
 
-func (d Database) CreatePoll(ctx context.Context, params CreatePollParams) (int64, error) {
+func (p CreatePollParams) actor() string {
+	if p.ActorID != "" {
+		return p.ActorID
+	}
+
+	return p.AuthorID
+}
+
+var ErrPollExists = errors.New("database: poll with the same question already exists")
+
+const uniqueViolationCode = "23505"
+
+func (d Database) CreatePoll(ctx context.Context, params CreatePollParams) (pollID int64, err error) {
 	tx, err := d.poll.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return 0, err
@@ -173,7 +340,11 @@ func (d Database) CreatePoll(ctx context.Context, params CreatePollParams) (int6
 		}
 	}()
 
-	q := database.New(tx)
+	return createPoll(ctx, database.New(tx), params)
+}
+
+// createPoll saves poll with answers and the first revision and records it in audit trail
+func createPoll(ctx context.Context, q *database.Queries, params CreatePollParams) (int64, error) {
 	pollID, err := q.CreatePoll(ctx, database.CreatePollParams{
 		Question: params.Question,
 		GuildID:  params.GuildID,
@@ -181,40 +352,121 @@ func (d Database) CreatePoll(ctx context.Context, params CreatePollParams) (int6
 		Duration: params.Duration,
 		IsMulti:  params.IsMulti,
 	})
+	if isUniqueViolation(err) {
+		return 0, errors.Join(ErrPollExists, err)
+	} else if err != nil {
+		return 0, err
+	}
+
+	if err = createPollOptions(ctx, q, pollID, params.Answers); err != nil {
+		return 0, err
+	}
+
+	if _, _, err = saveRevision(ctx, q, pollID, params); err != nil {
+		return 0, err
+	}
+
+	err = audit(ctx, q, AuditEntry{PollID: pollID, GuildID: params.GuildID, ActorID: params.actor(), Action: AuditCreated})
+	if err != nil {
+		return 0, err
+	}
+
+	return pollID, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
+
+func (d Database) OverwritePoll(ctx context.Context, params CreatePollParams) (pollID int64, err error) {
+	tx, err := d.poll.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, tx.Rollback(ctx))
+		} else {
+			err = errors.Join(err, tx.Commit(ctx))
+		}
+	}()
+
+	q := database.New(tx)
+	pollID, err = q.FindPollIDByQuestion(ctx, database.FindPollIDByQuestionParams{Question: params.Question, GuildID: params.GuildID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrPollNotFound
+	} else if err != nil {
+		return 0, err
+	}
+
+	if err = q.UpdatePoll(ctx, database.UpdatePollParams{ID: pollID, Question: params.Question, Duration: params.Duration, IsMulti: params.IsMulti}); err != nil {
+		return 0, err
+	}
+
+	if err = q.DeletePollOptions(ctx, pollID); err != nil {
+		return 0, err
+	}
+
+	if err = createPollOptions(ctx, q, pollID, params.Answers); err != nil {
+		return 0, err
+	}
+
+	if _, _, err = saveRevision(ctx, q, pollID, params); err != nil {
+		return 0, err
+	}
+
+	err = audit(ctx, q, AuditEntry{PollID: pollID, GuildID: params.GuildID, ActorID: params.actor(), Action: AuditEdited})
 	if err != nil {
 		return 0, err
 	}
 
-	for i, a := range params.Answers {
+	return pollID, nil
+}
+
+func createPollOptions(ctx context.Context, q *database.Queries, pollID int64, answers []AnswerParams) error {
+	for i, a := range answers {
 		if a.Text == "" {
-			return 0, fmt.Errorf("database: answer %d is empty string", i)
+			return fmt.Errorf("database: answer %d is empty string", i)
 		}
 
 		answer := database.CreatePollOptionParams{
-			Answer: a.Text,
-			Emoji:  ParseString(a.Emoji),
-			PollID: pollID,
+			Answer:        a.Text,
+			Emoji:         ParseString(a.Emoji.Name),
+			EmojiID:       ParseString(a.Emoji.ID),
+			EmojiAnimated: a.Emoji.Animated,
+			PollID:        pollID,
 		}
 
-		if err = q.CreatePollOption(ctx, answer); err != nil {
-			return 0, err
+		if err := q.CreatePollOption(ctx, answer); err != nil {
+			return err
 		}
 	}
 
-	return pollID, nil
+	return q.UpdatePollSearchText(ctx, pollID)
+}
+
+func (d Database) Close() {
+	d.poll.Close()
+}
+
+func (d Database) Ping(ctx context.Context) error {
+	return d.poll.Ping(ctx)
 }
 
-func NewDatabase(ctx context.Context) (*Database, error) {
-	url, ok := os.LookupEnv("DATABASE_URL")
-	if url != "" && !ok {
-		return nil, errors.New("database URL is not set. Please set DATABASE_URL environment variable")
+func (d Database) Stat() *pgxpool.Stat {
+	return d.poll.Stat()
+}
+
+func NewDatabase(ctx context.Context, url string) (*Database, error) {
+	cfg, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		return nil, err
 	}
+	cfg.ConnConfig.Tracer = tracing.QueryTracer{}
 
-	var (
-		err error
-		db  = new(Database)
-	)
-	db.poll, err = pgxpool.New(ctx, url)
+	db := new(Database)
+	db.poll, err = pgxpool.NewWithConfig(ctx, cfg)
 	if err != nil {
 		return nil, err
 	}
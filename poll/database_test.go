@@ -0,0 +1,49 @@
+package poll
+
+import (
+	"testing"
+
+	"github.com/wittano/yomoid/gen/database"
+)
+
+func TestSearchParamsFullText(t *testing.T) {
+	tests := []struct {
+		params SearchParams
+		want   bool
+	}{
+		{SearchParams{Title: "lunch"}, false},
+		{SearchParams{Title: "lunch", Mode: SearchFullText}, true},
+		// Polls filtered only by tag aren't ranked
+		{SearchParams{Title: "  ", Tag: "food", Mode: SearchFullText}, false},
+		{SearchParams{Tag: "food"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.params.fullText(); got != tt.want {
+			t.Errorf("%+v: fullText() = %v, want %v", tt.params, got, tt.want)
+		}
+	}
+}
+
+func TestCreatePollDataFromSearch(t *testing.T) {
+	row := database.SearchPollRow{
+		ID:            1,
+		Question:      "Lunch?",
+		VotingMethod:  string(Approval),
+		Answers:       []string{"pizza", "sushi"},
+		EmojiNames:    []string{"🍕", ""},
+		EmojiIds:      []string{"", ""},
+		EmojiAnimated: []bool{false, false},
+		Tags:          []string{"food"},
+		Snippet:       "**pizza**",
+	}
+
+	p := createPollData(database.FindPollByQuestionRow(row))
+	if p.ID != 1 || p.Method != Approval || p.Snippet != "**pizza**" || len(p.Tags) != 1 {
+		t.Fatalf("invalid poll %+v", p)
+	}
+
+	if len(p.Answers) != 2 || p.Answers[0].Emoji.Name != "🍕" || !p.Answers[1].Emoji.IsZero() {
+		t.Fatalf("invalid answers %+v", p.Answers)
+	}
+}
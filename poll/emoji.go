@@ -0,0 +1,78 @@
+package poll
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Emoji is unicode emoji or custom emoji of guild. Custom emoji has ID
+type Emoji struct {
+	ID       string
+	Name     string
+	Animated bool
+}
+
+func (e Emoji) IsZero() bool {
+	return e.ID == "" && e.Name == ""
+}
+
+func (e Emoji) IsCustom() bool {
+	return e.ID != ""
+}
+
+// String formats emoji like in message, e.g. <:yomoid:1234>. Unicode emoji is returned as it is
+func (e Emoji) String() string {
+	switch {
+	case !e.IsCustom():
+		return e.Name
+	case e.Animated:
+		return fmt.Sprintf("<a:%s:%s>", e.Name, e.ID)
+	default:
+		return fmt.Sprintf("<:%s:%s>", e.Name, e.ID)
+	}
+}
+
+// Component returns emoji of button, select menu option or native poll answer. It's nil for empty emoji
+func (e Emoji) Component() *discordgo.ComponentEmoji {
+	if e.IsZero() {
+		return nil
+	}
+
+	return &discordgo.ComponentEmoji{ID: e.ID, Name: e.Name, Animated: e.Animated}
+}
+
+var customEmojiRegex = regexp.MustCompile(`^<(a?):(\w{2,32}):(\d+)>$`)
+
+// ParseEmoji is inverse of Emoji.String
+func ParseEmoji(s string) Emoji {
+	s = strings.TrimSpace(s)
+	if match := customEmojiRegex.FindStringSubmatch(s); match != nil {
+		return Emoji{ID: match[3], Name: match[2], Animated: match[1] == "a"}
+	}
+
+	return Emoji{Name: s}
+}
+
+// NewEmoji maps emoji of native poll answer
+func NewEmoji(e *discordgo.ComponentEmoji) Emoji {
+	if e == nil {
+		return Emoji{}
+	}
+
+	return Emoji{ID: e.ID, Name: e.Name, Animated: e.Animated}
+}
+
+func newAnswers(texts, names, ids []string, animated []bool) []Answer {
+	answers := make([]Answer, len(texts))
+	for i, text := range texts {
+		answers[i].Text = text
+		if i < len(names) && i < len(ids) && i < len(animated) {
+			answers[i].Emoji = Emoji{ID: ids[i], Name: names[i], Animated: animated[i]}
+		}
+	}
+
+	return answers
+}
@@ -0,0 +1,35 @@
+package poll
+
+import "testing"
+
+func TestParseEmoji(t *testing.T) {
+	tests := []struct {
+		s    string
+		want Emoji
+	}{
+		{"🍕", Emoji{Name: "🍕"}},
+		{"<:yomoid:1234>", Emoji{ID: "1234", Name: "yomoid"}},
+		{"<a:party_parrot:5678>", Emoji{ID: "5678", Name: "party_parrot", Animated: true}},
+		{"", Emoji{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			e := ParseEmoji(test.s)
+			if e != test.want {
+				t.Fatalf("emoji = %+v, want %+v", e, test.want)
+			}
+
+			if e.String() != test.s {
+				t.Fatalf("String() = %q, want %q", e.String(), test.s)
+			}
+		})
+	}
+}
+
+func TestNewAnswers(t *testing.T) {
+	answers := newAnswers([]string{"yes", "no  maybe"}, []string{"yomoid", ""}, []string{"1234", ""}, []bool{true, false})
+	if answers[0].Emoji != (Emoji{ID: "1234", Name: "yomoid", Animated: true}) || answers[1].Text != "no  maybe" || !answers[1].Emoji.IsZero() {
+		t.Fatalf("invalid answers: %+v", answers)
+	}
+}
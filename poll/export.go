@@ -0,0 +1,229 @@
+package poll
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/wittano/yomoid/gen/database"
+)
+
+// ExportVersion is version of JSON format of exported templates
+const ExportVersion = 1
+
+// Limits of imported templates. Question and answers are limited like columns of poll and poll_option tables
+const (
+	MaxImportedPolls  = 100
+	maxQuestionLength = 300
+	maxAnswerLength   = 55
+)
+
+var ErrInvalidImport = errors.New("invalid imported templates")
+
+// Export is JSON document with templates of guild
+type Export struct {
+	Version int            `json:"version"`
+	Polls   []ExportedPoll `json:"polls"`
+}
+
+// ExportedPoll is template without IDs, so it can be imported in other guild
+type ExportedPoll struct {
+	Question    string           `json:"question"`
+	Answers     []ExportedAnswer `json:"answers"`
+	Duration    int16            `json:"duration"`
+	Multiselect bool             `json:"multiselect"`
+	Method      VotingMethod     `json:"method,omitempty"`
+	Tags        []ExportedTag    `json:"tags,omitempty"`
+}
+
+type ExportedAnswer struct {
+	Text string `json:"text"`
+	// Emoji is formatted like in message, e.g. <:yomoid:1234>
+	Emoji string `json:"emoji,omitempty"`
+}
+
+type ExportedTag struct {
+	Name string `json:"name"`
+	// Weight is chance of picking poll from tag pool by /poll random. Zero means MinTagWeight
+	Weight int16 `json:"weight,omitempty"`
+}
+
+// ImportError tells, which imported template couldn't be saved
+type ImportError struct {
+	Question string
+	Err      error
+}
+
+func (e ImportError) Error() string {
+	return fmt.Sprintf("import %q: %s", e.Question, e.Err)
+}
+
+func (e ImportError) Unwrap() error {
+	return e.Err
+}
+
+// ParseExport decodes and validates exported templates. Tags are normalized
+func ParseExport(r io.Reader) ([]ExportedPoll, error) {
+	var e Export
+	if err := json.NewDecoder(r).Decode(&e); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidImport, err)
+	}
+
+	switch {
+	case e.Version != ExportVersion:
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidImport, e.Version)
+	case len(e.Polls) == 0:
+		return nil, fmt.Errorf("%w: file doesn't contain templates", ErrInvalidImport)
+	case len(e.Polls) > MaxImportedPolls:
+		return nil, fmt.Errorf("%w: file contains more than %d templates", ErrInvalidImport, MaxImportedPolls)
+	}
+
+	for i := range e.Polls {
+		if err := e.Polls[i].normalize(); err != nil {
+			return nil, fmt.Errorf("%w: template %d: %s", ErrInvalidImport, i+1, err)
+		}
+	}
+
+	return e.Polls, nil
+}
+
+func (p *ExportedPoll) normalize() error {
+	p.Question = strings.TrimSpace(p.Question)
+	switch {
+	case p.Question == "" || utf8.RuneCountInString(p.Question) > maxQuestionLength:
+		return fmt.Errorf("question must have from 1 to %d characters", maxQuestionLength)
+	case len(p.Answers) == 0 || len(p.Answers) > MaxCustomAnswers:
+		return fmt.Errorf("template must have from 1 to %d answers", MaxCustomAnswers)
+	case !slices.Contains(Durations, p.Duration):
+		return fmt.Errorf("duration %d isn't supported by Discord", p.Duration)
+	case p.Method != "" && !p.Method.Valid():
+		return fmt.Errorf("unknown voting method %q", p.Method)
+	}
+
+	for _, a := range p.Answers {
+		if a.Text == "" || utf8.RuneCountInString(a.Text) > maxAnswerLength {
+			return fmt.Errorf("answer must have from 1 to %d characters", maxAnswerLength)
+		}
+	}
+
+	tags := make([]ExportedTag, 0, len(p.Tags))
+	for _, t := range p.Tags {
+		name, err := NormalizeTag(t.Name)
+		if err != nil {
+			return err
+		}
+
+		if t.Weight == 0 {
+			t.Weight = MinTagWeight
+		} else if t.Weight < MinTagWeight || t.Weight > MaxTagWeight {
+			return fmt.Errorf("weight of tag %s must be from %d to %d", name, MinTagWeight, MaxTagWeight)
+		}
+
+		if !slices.ContainsFunc(tags, func(t ExportedTag) bool { return t.Name == name }) {
+			tags = append(tags, ExportedTag{Name: name, Weight: t.Weight})
+		}
+	}
+	p.Tags = tags
+
+	return nil
+}
+
+// ExportPolls returns templates of guild with tags. Empty tag exports all templates
+func (d Database) ExportPolls(ctx context.Context, guildID, tag string) (Export, error) {
+	data, err := database.New(d.poll).FindGuildPollsToExport(ctx, database.FindGuildPollsToExportParams{GuildID: guildID, Tag: tag})
+	if err != nil {
+		return Export{}, err
+	}
+
+	e := Export{Version: ExportVersion, Polls: make([]ExportedPoll, len(data))}
+	for i, p := range data {
+		answers := newAnswers(p.Answers, p.EmojiNames, p.EmojiIds, p.EmojiAnimated)
+		exported := ExportedPoll{
+			Question:    p.Question,
+			Answers:     make([]ExportedAnswer, len(answers)),
+			Duration:    p.Duration,
+			Multiselect: p.IsMulti,
+			Method:      newVotingMethod(p.VotingMethod),
+			Tags:        make([]ExportedTag, len(p.Tags)),
+		}
+		for j, a := range answers {
+			exported.Answers[j] = ExportedAnswer{Text: a.Text, Emoji: a.Emoji.String()}
+		}
+		for j, name := range p.Tags {
+			exported.Tags[j].Name = name
+			if j < len(p.TagWeights) {
+				exported.Tags[j].Weight = p.TagWeights[j]
+			}
+		}
+
+		e.Polls[i] = exported
+	}
+
+	return e, nil
+}
+
+// ImportPolls saves templates parsed by ParseExport with their tags. Templates are saved in single transaction, so
+// nothing is imported, when any template can't be saved. Importing user is author of templates
+func (d Database) ImportPolls(ctx context.Context, guildID, actorID string, polls []ExportedPoll) (ids []int64, err error) {
+	tx, err := d.poll.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, tx.Rollback(ctx))
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	q := database.New(tx)
+	ids = make([]int64, len(polls))
+	for i, p := range polls {
+		if ids[i], err = importPoll(ctx, q, guildID, actorID, p); err != nil {
+			return nil, ImportError{Question: p.Question, Err: err}
+		}
+	}
+
+	return ids, nil
+}
+
+func importPoll(ctx context.Context, q *database.Queries, guildID, actorID string, p ExportedPoll) (int64, error) {
+	answers := make([]AnswerParams, len(p.Answers))
+	for i, a := range p.Answers {
+		answers[i] = AnswerParams{Text: a.Text, Emoji: ParseEmoji(a.Emoji)}
+	}
+
+	pollID, err := createPoll(ctx, q, CreatePollParams{
+		Question: p.Question,
+		GuildID:  guildID,
+		AuthorID: actorID,
+		Duration: p.Duration,
+		IsMulti:  p.Multiselect,
+		Answers:  answers,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if p.Method != "" && p.Method != Plurality {
+		_, err = q.UpdatePollVotingMethod(ctx, database.UpdatePollVotingMethodParams{ID: pollID, GuildID: guildID, VotingMethod: string(p.Method)})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	for _, t := range p.Tags {
+		if err = addTag(ctx, q, guildID, pollID, t.Name, t.Weight); err != nil {
+			return 0, err
+		}
+	}
+
+	return pollID, nil
+}
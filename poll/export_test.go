@@ -0,0 +1,63 @@
+package poll
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseExport(t *testing.T) {
+	input := `{"version":1,"polls":[{"question":" Lunch? ","answers":[{"text":"pizza","emoji":"<:pizza:123>"},{"text":"sushi"}],
+		"duration":24,"method":"approval","tags":[{"name":"Food Poll","weight":3},{"name":"food-poll"},{"name":"daily"}]}]}`
+
+	polls, err := ParseExport(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := polls[0]
+	if p.Question != "Lunch?" || p.Method != Approval || len(p.Answers) != 2 {
+		t.Fatalf("invalid poll: %+v", p)
+	}
+
+	want := []ExportedTag{{Name: "food-poll", Weight: 3}, {Name: "daily", Weight: MinTagWeight}}
+	if !slices.Equal(p.Tags, want) {
+		t.Fatalf("tags = %+v, want %+v", p.Tags, want)
+	}
+}
+
+func TestParseExportInvalid(t *testing.T) {
+	valid := ExportedPoll{Question: "Lunch?", Answers: []ExportedAnswer{{Text: "pizza"}}, Duration: 24}
+
+	tests := map[string]func(e *Export){
+		"version":  func(e *Export) { e.Version = 2 },
+		"empty":    func(e *Export) { e.Polls = nil },
+		"question": func(e *Export) { e.Polls[0].Question = " " },
+		"answers":  func(e *Export) { e.Polls[0].Answers = nil },
+		"answer":   func(e *Export) { e.Polls[0].Answers[0].Text = strings.Repeat("a", maxAnswerLength+1) },
+		"duration": func(e *Export) { e.Polls[0].Duration = 5 },
+		"method":   func(e *Export) { e.Polls[0].Method = "borda" },
+		"tag":      func(e *Export) { e.Polls[0].Tags = []ExportedTag{{Name: "#lunch"}} },
+		"weight":   func(e *Export) { e.Polls[0].Tags = []ExportedTag{{Name: "lunch", Weight: MaxTagWeight + 1}} },
+	}
+
+	for name, change := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := valid
+			p.Answers = slices.Clone(valid.Answers)
+			e := Export{Version: ExportVersion, Polls: []ExportedPoll{p}}
+			change(&e)
+
+			data, err := json.Marshal(e)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err = ParseExport(strings.NewReader(string(data))); !errors.Is(err, ErrInvalidImport) {
+				t.Fatalf("expected invalid import error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package poll
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidPlaceholder = errors.New("invalid placeholder")
+
+// DefaultDateFormat is used by {{date}} placeholder without format argument
+const DefaultDateFormat = "YYYY-MM-DD"
+
+// dateFormat converts user-friendly date tokens into Go time layout. Longer tokens must be before shorter ones
+var dateFormat = strings.NewReplacer(
+	"YYYY", "2006",
+	"YY", "06",
+	"MMMM", "January",
+	"MMM", "Jan",
+	"MM", "01",
+	"M", "1",
+	"dddd", "Monday",
+	"ddd", "Mon",
+	"DD", "02",
+	"D", "2",
+	"hh", "15",
+	"mm", "04",
+)
+
+// Variables are values of placeholders available in question and answers of poll
+type Variables struct {
+	Now     time.Time
+	Channel string
+	Poster  string
+	// Count is number of previous posts of poll
+	Count int64
+}
+
+// Render replaces placeholders, e.g. {{weekday}}, {{date +1d "DD.MM"}}, {{channel}}, {{poster}} or {{count}}, in text
+func Render(text string, vars Variables) (string, error) {
+	var b strings.Builder
+
+	for {
+		start := strings.Index(text, "{{")
+		if start < 0 {
+			b.WriteString(text)
+			break
+		}
+
+		end := strings.Index(text[start:], "}}")
+		if end < 0 {
+			return "", fmt.Errorf("%w: missing closing }} in %q", ErrInvalidPlaceholder, text[start:])
+		}
+		end += start
+
+		value, err := renderPlaceholder(text[start+2:end], vars)
+		if err != nil {
+			return "", err
+		}
+
+		b.WriteString(text[:start])
+		b.WriteString(value)
+		text = text[end+2:]
+	}
+
+	return b.String(), nil
+}
+
+func renderPlaceholder(placeholder string, vars Variables) (string, error) {
+	args, err := splitArgs(placeholder)
+	if err != nil {
+		return "", err
+	} else if len(args) == 0 {
+		return "", fmt.Errorf("%w: empty {{}}", ErrInvalidPlaceholder)
+	}
+
+	name, args := args[0], args[1:]
+	switch name {
+	case "date":
+		return renderDate(name, args, vars.Now, DefaultDateFormat)
+	case "weekday":
+		if len(args) > 1 {
+			return "", fmt.Errorf("%w: {{weekday}} accepts only offset argument", ErrInvalidPlaceholder)
+		}
+		return renderDate(name, args, vars.Now, "dddd")
+	case "channel":
+		return vars.Channel, noArgs(name, args)
+	case "poster":
+		return vars.Poster, noArgs(name, args)
+	case "count":
+		return strconv.FormatInt(vars.Count+1, 10), noArgs(name, args)
+	default:
+		return "", fmt.Errorf("%w: unknown variable %q. Use date, weekday, channel, poster or count", ErrInvalidPlaceholder, name)
+	}
+}
+
+func noArgs(name string, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("%w: {{%s}} doesn't accept arguments", ErrInvalidPlaceholder, name)
+	}
+
+	return nil
+}
+
+// renderDate accepts optional offset, e.g. +1d, -2w, +1m, +1y, and optional format
+func renderDate(name string, args []string, now time.Time, format string) (string, error) {
+	if len(args) > 0 && (strings.HasPrefix(args[0], "+") || strings.HasPrefix(args[0], "-")) {
+		var err error
+		if now, err = addOffset(now, args[0]); err != nil {
+			return "", fmt.Errorf("%w: {{%s}}: %w", ErrInvalidPlaceholder, name, err)
+		}
+		args = args[1:]
+	}
+
+	if len(args) == 1 {
+		format = args[0]
+	} else if len(args) > 1 {
+		return "", fmt.Errorf("%w: {{%s}} accepts offset and format arguments only", ErrInvalidPlaceholder, name)
+	}
+
+	return now.Format(dateFormat.Replace(format)), nil
+}
+
+func addOffset(t time.Time, offset string) (time.Time, error) {
+	if len(offset) < 3 {
+		return t, fmt.Errorf("invalid offset %q", offset)
+	}
+
+	n, err := strconv.Atoi(offset[:len(offset)-1])
+	if err != nil {
+		return t, fmt.Errorf("invalid offset %q", offset)
+	}
+
+	switch offset[len(offset)-1] {
+	case 'd':
+		return t.AddDate(0, 0, n), nil
+	case 'w':
+		return t.AddDate(0, 0, 7*n), nil
+	case 'm':
+		return t.AddDate(0, n, 0), nil
+	case 'y':
+		return t.AddDate(n, 0, 0), nil
+	default:
+		return t, fmt.Errorf("invalid offset unit in %q. Use d, w, m or y", offset)
+	}
+}
+
+// splitArgs splits placeholder by whitespaces. Argument with whitespaces has to be quoted
+func splitArgs(s string) (args []string, err error) {
+	s = strings.TrimSpace(s)
+	for s != "" {
+		if s[0] == '"' {
+			end := strings.IndexByte(s[1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: missing closing quote in %q", ErrInvalidPlaceholder, s)
+			}
+
+			args = append(args, s[1:end+1])
+			s = s[end+2:]
+		} else {
+			end := strings.IndexAny(s, " \t")
+			if end < 0 {
+				end = len(s)
+			}
+
+			args = append(args, s[:end])
+			s = s[end:]
+		}
+
+		s = strings.TrimLeft(s, " \t")
+	}
+
+	return
+}
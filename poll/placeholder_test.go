@@ -0,0 +1,54 @@
+package poll
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	vars := Variables{
+		Now:     time.Date(2026, time.October, 19, 12, 30, 0, 0, time.UTC),
+		Channel: "general",
+		Poster:  "wittano",
+		Count:   4,
+	}
+
+	tests := []struct {
+		text, expected string
+	}{
+		{"Lunch?", "Lunch?"},
+		{"Lunch on {{weekday}} {{date}}?", "Lunch on Monday 2026-10-19?"},
+		{"{{ weekday +1d }}", "Tuesday"},
+		{"{{date +1w \"DD.MM\"}}", "26.10"},
+		{"{{date -1m \"D MMMM YYYY\"}}", "19 September 2026"},
+		{"{{date \"ddd hh:mm\"}}", "Mon 12:30"},
+		{"#{{count}} in {{channel}} by {{poster}}", "#5 in general by wittano"},
+	}
+
+	for _, test := range tests {
+		got, err := Render(test.text, vars)
+		if err != nil {
+			t.Errorf("%q: %v", test.text, err)
+		} else if got != test.expected {
+			t.Errorf("%q: got %q, expected %q", test.text, got, test.expected)
+		}
+	}
+}
+
+func TestRenderInvalid(t *testing.T) {
+	for _, text := range []string{
+		"{{unknown}}",
+		"{{date",
+		"{{}}",
+		"{{date +1x}}",
+		"{{date +d}}",
+		"{{date \"DD}}",
+		"{{weekday +1d \"DD\"}}",
+		"{{channel general}}",
+	} {
+		if _, err := Render(text, Variables{}); !errors.Is(err, ErrInvalidPlaceholder) {
+			t.Errorf("%q: expected invalid placeholder error, got: %v", text, err)
+		}
+	}
+}
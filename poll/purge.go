@@ -0,0 +1,82 @@
+package poll
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/wittano/yomoid/gen/database"
+	"github.com/wittano/yomoid/metrics"
+)
+
+// PurgeDeletedPolls permanently removes polls deleted before given time
+func (d Database) PurgeDeletedPolls(ctx context.Context, before time.Time) (n int64, err error) {
+	tx, err := d.poll.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, tx.Rollback(ctx))
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	q := database.New(tx)
+	deletedAt := pgtype.Timestamptz{Time: before, Valid: true}
+	if err = q.PurgeDeletedPollOptions(ctx, deletedAt); err != nil {
+		return 0, err
+	}
+
+	if err = q.PurgeDeletedPollPosts(ctx, deletedAt); err != nil {
+		return 0, err
+	}
+
+	if err = q.PurgeDeletedPollRevisions(ctx, deletedAt); err != nil {
+		return 0, err
+	}
+
+	return q.PurgeDeletedPolls(ctx, deletedAt)
+}
+
+// Purger periodically removes polls, which were deleted earlier than Retention
+type Purger struct {
+	Db        *Database
+	Retention time.Duration
+	Interval  time.Duration
+}
+
+// Run blocks until ctx is cancelled
+func (p Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+
+	for {
+		p.purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p Purger) purge(ctx context.Context) {
+	n, err := p.Db.PurgeDeletedPolls(ctx, time.Now().Add(-p.Retention))
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			slog.ErrorContext(ctx, "failed purge deleted polls", "error", err)
+		}
+		return
+	}
+
+	if n > 0 {
+		slog.InfoContext(ctx, "deleted polls purged", "count", n)
+		metrics.PollsPurged.Add(float64(n))
+	}
+}
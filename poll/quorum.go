@@ -0,0 +1,132 @@
+package poll
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/wittano/yomoid/gen/database"
+	"github.com/wittano/yomoid/tracing"
+)
+
+// Quorum is minimal number of voters, which makes result of posted poll valid. Poll without Required and Percent
+// doesn't have quorum
+type Quorum struct {
+	// Required is zero for percent quorum of poll, which wasn't closed yet
+	Required int32
+	// Percent of RoleID members, from which Required is computed, when poll closes. Zero means absolute quorum
+	Percent int16
+	RoleID  string
+	// Repost posts poll again, when quorum wasn't reached
+	Repost bool
+}
+
+// RequiredVoters computes quorum as percent of members rounded up
+func RequiredVoters(percent int16, members int) int32 {
+	return int32((members*int(percent) + 99) / 100)
+}
+
+func (q Quorum) Enabled() bool {
+	return q.Required > 0 || q.Percent > 0
+}
+
+func (q Quorum) String() string {
+	if q.Percent > 0 && q.Required == 0 {
+		return fmt.Sprintf("%d%% of <@&%s>", q.Percent, q.RoleID)
+	} else if q.Percent > 0 {
+		return fmt.Sprintf("%d voters (%d%% of <@&%s>)", q.Required, q.Percent, q.RoleID)
+	}
+
+	return fmt.Sprintf("%d voters", q.Required)
+}
+
+func quorumParams(q Quorum) (pgtype.Int4, pgtype.Int2, pgtype.Text) {
+	return pgtype.Int4{Int32: q.Required, Valid: q.Required > 0},
+		pgtype.Int2{Int16: q.Percent, Valid: q.Percent > 0},
+		ParseString(q.RoleID)
+}
+
+func (d Database) RecordVote(ctx context.Context, channelID, messageID, userID string, answerID int) error {
+	return database.New(d.poll).CreatePollVote(ctx, database.CreatePollVoteParams{
+		ChannelID: channelID,
+		MessageID: messageID,
+		UserID:    userID,
+		AnswerID:  int32(answerID),
+	})
+}
+
+func (d Database) RemoveVote(ctx context.Context, channelID, messageID, userID string, answerID int) error {
+	return database.New(d.poll).DeletePollVote(ctx, database.DeletePollVoteParams{
+		ChannelID: channelID,
+		MessageID: messageID,
+		UserID:    userID,
+		AnswerID:  int32(answerID),
+	})
+}
+
+// CountVoters returns number of unique members, who voted in posted poll
+func (d Database) CountVoters(ctx context.Context, postID int64) (int64, error) {
+	return database.New(d.poll).CountPollVoters(ctx, postID)
+}
+
+// FindPostsToCheckQuorum returns posts with quorum, which polls closed before now
+func (d Database) FindPostsToCheckQuorum(ctx context.Context, now time.Time) ([]Post, error) {
+	data, err := database.New(d.poll).FindPollPostsToCheckQuorum(ctx, pgtype.Timestamptz{Time: now, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	posts := make([]Post, len(data))
+	for i, p := range data {
+		posts[i] = newPost(p)
+	}
+
+	return posts, nil
+}
+
+// FinishQuorum saves result of quorum check. Required voters are saved for percent quorum. Nil met means quorum
+// couldn't be checked
+func (d Database) FinishQuorum(ctx context.Context, postID int64, required int32, met *bool) error {
+	params := database.FinishPollPostQuorumParams{
+		ID:             postID,
+		QuorumRequired: pgtype.Int4{Int32: required, Valid: required > 0},
+	}
+	if met != nil {
+		params.QuorumMet = pgtype.Bool{Bool: *met, Valid: true}
+	}
+
+	return database.New(d.poll).FinishPollPostQuorum(ctx, params)
+}
+
+// FailQuorum counts failed quorum check of post. It returns number of failed checks
+func (d Database) FailQuorum(ctx context.Context, postID int64) (int16, error) {
+	return database.New(d.poll).FailPollPostQuorum(ctx, postID)
+}
+
+// VoteHandler tracks votes of posted polls with quorum
+type VoteHandler struct {
+	Db Queries
+}
+
+func (h VoteHandler) VoteAdd(ctx context.Context, _ *discordgo.Session, e *discordgo.MessagePollVoteAdd) {
+	ctx, span := tracing.StartEvent(ctx, "event MESSAGE_POLL_VOTE_ADD", e.GuildID, e.ChannelID)
+	defer span.End()
+
+	if err := h.Db.RecordVote(ctx, e.ChannelID, e.MessageID, e.UserID, e.AnswerID); err != nil {
+		slog.ErrorContext(ctx, "failed record poll vote", "guildID", e.GuildID, "channelID", e.ChannelID, "messageID", e.MessageID, "error", err)
+		tracing.RecordError(span, err)
+	}
+}
+
+func (h VoteHandler) VoteRemove(ctx context.Context, _ *discordgo.Session, e *discordgo.MessagePollVoteRemove) {
+	ctx, span := tracing.StartEvent(ctx, "event MESSAGE_POLL_VOTE_REMOVE", e.GuildID, e.ChannelID)
+	defer span.End()
+
+	if err := h.Db.RemoveVote(ctx, e.ChannelID, e.MessageID, e.UserID, e.AnswerID); err != nil {
+		slog.ErrorContext(ctx, "failed remove poll vote", "guildID", e.GuildID, "channelID", e.ChannelID, "messageID", e.MessageID, "error", err)
+		tracing.RecordError(span, err)
+	}
+}
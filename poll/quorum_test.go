@@ -0,0 +1,39 @@
+package poll
+
+import "testing"
+
+func TestRequiredVoters(t *testing.T) {
+	tests := []struct {
+		percent  int16
+		members  int
+		expected int32
+	}{
+		{50, 10, 5},
+		{50, 11, 6},
+		{1, 1, 1},
+		{100, 7, 7},
+		{30, 0, 0},
+	}
+
+	for _, test := range tests {
+		if got := RequiredVoters(test.percent, test.members); got != test.expected {
+			t.Errorf("%d%% of %d members: got %d, expected %d", test.percent, test.members, got, test.expected)
+		}
+	}
+}
+
+func TestQuorumString(t *testing.T) {
+	q := Quorum{Percent: 50, RoleID: "1"}
+	if !q.Enabled() || q.String() != "50% of <@&1>" {
+		t.Fatalf("invalid unresolved quorum: %q", q)
+	}
+
+	q.Required = 3
+	if q.String() != "3 voters (50% of <@&1>)" {
+		t.Fatalf("invalid resolved quorum: %q", q)
+	}
+
+	if (Quorum{}).Enabled() {
+		t.Fatal("empty quorum is enabled")
+	}
+}
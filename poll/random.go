@@ -0,0 +1,138 @@
+package poll
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/wittano/yomoid/gen/database"
+)
+
+var ErrNoPollCandidates = errors.New("database: all polls with tag were posted recently or tag is empty")
+
+// Schedule posts random poll with tag to channel every day at hour in UTC
+type Schedule struct {
+	ID        int64
+	GuildID   string
+	ChannelID string
+	Tag       string
+	Hour      int16
+	// AvoidDays is window, in which posted polls aren't picked again. Zero means default window
+	AvoidDays int16
+	NextRunAt time.Time
+	CreatedBy string
+}
+
+type candidate struct {
+	id     int64
+	weight int16
+}
+
+// PickRandomPoll returns ID of poll with tag, drawn with weights of tag. Polls posted after since are skipped
+func (d Database) PickRandomPoll(ctx context.Context, guildID, tag string, since time.Time) (int64, error) {
+	data, err := database.New(d.poll).FindRandomPollCandidates(ctx, database.FindRandomPollCandidatesParams{
+		GuildID:  guildID,
+		Name:     tag,
+		PostedAt: pgtype.Timestamptz{Time: since, Valid: true},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	candidates := make([]candidate, len(data))
+	for i, c := range data {
+		candidates[i] = candidate{id: c.ID, weight: c.Weight}
+	}
+
+	return pickWeighted(candidates, rand.IntN)
+}
+
+// pickWeighted draws candidate with probability proportional to its weight. intN returns number in [0, n)
+func pickWeighted(candidates []candidate, intN func(n int) int) (int64, error) {
+	var total int
+	for _, c := range candidates {
+		total += int(max(c.weight, 1))
+	}
+
+	if total == 0 {
+		return 0, ErrNoPollCandidates
+	}
+
+	n := intN(total)
+	for _, c := range candidates {
+		if n -= int(max(c.weight, 1)); n < 0 {
+			return c.id, nil
+		}
+	}
+
+	return candidates[len(candidates)-1].id, nil
+}
+
+// NextRun returns the first time after now at hour in UTC
+func NextRun(now time.Time, hour int16) time.Time {
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), int(hour), 0, 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+
+	return next
+}
+
+func (d Database) SaveSchedule(ctx context.Context, s Schedule) error {
+	return database.New(d.poll).SavePollSchedule(ctx, database.SavePollScheduleParams{
+		GuildID:   s.GuildID,
+		ChannelID: s.ChannelID,
+		Tag:       s.Tag,
+		Hour:      s.Hour,
+		AvoidDays: s.AvoidDays,
+		NextRunAt: pgtype.Timestamptz{Time: s.NextRunAt, Valid: true},
+		CreatedBy: s.CreatedBy,
+	})
+}
+
+var ErrScheduleNotFound = errors.New("database: schedule not found")
+
+func (d Database) DeleteSchedule(ctx context.Context, guildID, channelID string) error {
+	n, err := database.New(d.poll).DeletePollSchedule(ctx, database.DeletePollScheduleParams{GuildID: guildID, ChannelID: channelID})
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return ErrScheduleNotFound
+	}
+
+	return nil
+}
+
+// FindDueSchedules returns schedules, which should be run before now
+func (d Database) FindDueSchedules(ctx context.Context, now time.Time) ([]Schedule, error) {
+	data, err := database.New(d.poll).FindDuePollSchedules(ctx, pgtype.Timestamptz{Time: now, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	schedules := make([]Schedule, len(data))
+	for i, s := range data {
+		schedules[i] = Schedule{
+			ID:        s.ID,
+			GuildID:   s.GuildID,
+			ChannelID: s.ChannelID,
+			Tag:       s.Tag,
+			Hour:      s.Hour,
+			AvoidDays: s.AvoidDays,
+			NextRunAt: s.NextRunAt.Time,
+			CreatedBy: s.CreatedBy,
+		}
+	}
+
+	return schedules, nil
+}
+
+func (d Database) UpdateScheduleNextRun(ctx context.Context, id int64, next time.Time) error {
+	return database.New(d.poll).UpdatePollScheduleNextRun(ctx, database.UpdatePollScheduleNextRunParams{
+		ID:        id,
+		NextRunAt: pgtype.Timestamptz{Time: next, Valid: true},
+	})
+}
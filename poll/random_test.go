@@ -0,0 +1,42 @@
+package poll
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPickWeighted(t *testing.T) {
+	candidates := []candidate{{id: 1, weight: 1}, {id: 2, weight: 3}, {id: 3, weight: 1}}
+
+	expected := map[int]int64{0: 1, 1: 2, 3: 2, 4: 3}
+	for n, id := range expected {
+		got, err := pickWeighted(candidates, func(total int) int {
+			if total != 5 {
+				t.Fatalf("invalid total weight %d", total)
+			}
+			return n
+		})
+		if err != nil {
+			t.Fatal(err)
+		} else if got != id {
+			t.Errorf("n=%d: got poll %d, expected %d", n, got, id)
+		}
+	}
+
+	if _, err := pickWeighted(nil, func(int) int { return 0 }); !errors.Is(err, ErrNoPollCandidates) {
+		t.Fatalf("expected no candidates error, got: %v", err)
+	}
+}
+
+func TestNextRun(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 9, 30, 0, 0, time.UTC)
+
+	if next := NextRun(now, 12); !next.Equal(time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("invalid next run later today: %s", next)
+	}
+
+	if next := NextRun(now, 9); !next.Equal(time.Date(2026, time.October, 20, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("invalid next run tomorrow: %s", next)
+	}
+}
@@ -0,0 +1,200 @@
+package poll
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/wittano/yomoid/gen/database"
+)
+
+var (
+	ErrInvalidReminder  = errors.New("poll: invalid reminder")
+	ErrReminderNotFound = errors.New("database: reminder not found")
+)
+
+// MaxReminders limits reminders of single posted poll
+const MaxReminders = 5
+
+// ReminderLead describes, when reminder is sent before poll closes. It's either fixed time before close or percent
+// of poll duration, which has elapsed since posting
+type ReminderLead struct {
+	Before  time.Duration
+	Percent int
+}
+
+// ParseReminderLeads parses comma separated leads, e.g. "2h, 50%"
+func ParseReminderLeads(s string) ([]ReminderLead, error) {
+	var leads []ReminderLead
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		lead, err := parseReminderLead(part)
+		if err != nil {
+			return nil, err
+		}
+
+		leads = append(leads, lead)
+	}
+
+	if len(leads) == 0 {
+		return nil, fmt.Errorf("%w: pass time before close, e.g. 2h, or percent of duration, e.g. 50%%", ErrInvalidReminder)
+	} else if len(leads) > MaxReminders {
+		return nil, fmt.Errorf("%w: poll can have up to %d reminders", ErrInvalidReminder, MaxReminders)
+	}
+
+	return leads, nil
+}
+
+func parseReminderLead(s string) (ReminderLead, error) {
+	if p, ok := strings.CutSuffix(s, "%"); ok {
+		percent, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || percent <= 0 || percent >= 100 {
+			return ReminderLead{}, fmt.Errorf("%w: %q must be percent between 1%% and 99%%", ErrInvalidReminder, s)
+		}
+
+		return ReminderLead{Percent: percent}, nil
+	}
+
+	before, err := time.ParseDuration(s)
+	if err != nil || before <= 0 {
+		return ReminderLead{}, fmt.Errorf("%w: %q isn't valid time before close, e.g. 2h or 30m", ErrInvalidReminder, s)
+	}
+
+	return ReminderLead{Before: before}, nil
+}
+
+// RemindAt computes time of reminder of poll posted at postedAt, which closes at closesAt
+func (l ReminderLead) RemindAt(postedAt, closesAt time.Time) (time.Time, error) {
+	duration := closesAt.Sub(postedAt)
+
+	var at time.Time
+	if l.Percent > 0 {
+		at = postedAt.Add(duration * time.Duration(l.Percent) / 100)
+	} else {
+		at = closesAt.Add(-l.Before)
+	}
+
+	if !at.After(postedAt) || !at.Before(closesAt) {
+		return at, fmt.Errorf("%w: reminder %s is outside of poll duration %s", ErrInvalidReminder, l, duration)
+	}
+
+	return at, nil
+}
+
+func (l ReminderLead) String() string {
+	if l.Percent > 0 {
+		return fmt.Sprintf("at %d%% of duration", l.Percent)
+	}
+
+	return fmt.Sprintf("%s before close", l.Before)
+}
+
+// Reminder pings role in channel of posted poll
+type Reminder struct {
+	ID        int64
+	PostID    int64
+	PollID    int64
+	GuildID   string
+	ChannelID string
+	MessageID string
+	RoleID    string
+	RemindAt  time.Time
+	// MinTurnout skips reminder, when poll has at least that many votes. Zero means reminder is always sent
+	MinTurnout int32
+	CreatedBy  string
+}
+
+func newReminder(r database.FindDuePollRemindersRow) Reminder {
+	return Reminder{
+		ID:         r.ID,
+		PostID:     r.PostID,
+		PollID:     r.PollID,
+		GuildID:    r.GuildID,
+		ChannelID:  r.ChannelID,
+		MessageID:  r.MessageID,
+		RoleID:     r.RoleID,
+		RemindAt:   r.RemindAt.Time,
+		MinTurnout: r.MinTurnout,
+		CreatedBy:  r.CreatedBy,
+	}
+}
+
+func createReminders(ctx context.Context, q *database.Queries, postID int64, reminders []Reminder) error {
+	for _, r := range reminders {
+		err := q.CreatePollReminder(ctx, database.CreatePollReminderParams{
+			PostID:     postID,
+			GuildID:    r.GuildID,
+			ChannelID:  r.ChannelID,
+			MessageID:  r.MessageID,
+			RoleID:     r.RoleID,
+			RemindAt:   pgtype.Timestamptz{Time: r.RemindAt, Valid: true},
+			MinTurnout: r.MinTurnout,
+			CreatedBy:  r.CreatedBy,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FindReminders returns pending reminders of guild
+func (d Database) FindReminders(ctx context.Context, guildID string) ([]Reminder, error) {
+	data, err := database.New(d.poll).FindPendingPollReminders(ctx, guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	reminders := make([]Reminder, len(data))
+	for i, r := range data {
+		reminders[i] = newReminder(database.FindDuePollRemindersRow(r))
+	}
+
+	return reminders, nil
+}
+
+// CancelReminder removes pending reminder. It returns ErrReminderNotFound, if reminder was already sent
+func (d Database) CancelReminder(ctx context.Context, guildID string, id int64) error {
+	n, err := database.New(d.poll).DeletePollReminder(ctx, database.DeletePollReminderParams{GuildID: guildID, ID: id})
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return ErrReminderNotFound
+	}
+
+	return nil
+}
+
+// FindDueReminders returns pending reminders, which should be sent before now
+func (d Database) FindDueReminders(ctx context.Context, now time.Time) ([]Reminder, error) {
+	data, err := database.New(d.poll).FindDuePollReminders(ctx, pgtype.Timestamptz{Time: now, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	reminders := make([]Reminder, len(data))
+	for i, r := range data {
+		reminders[i] = newReminder(r)
+	}
+
+	return reminders, nil
+}
+
+// FinishReminder marks reminder as sent or skipped, so it isn't sent again
+func (d Database) FinishReminder(ctx context.Context, id int64, skipped bool) error {
+	return database.New(d.poll).FinishPollReminder(ctx, database.FinishPollReminderParams{ID: id, Skipped: skipped})
+}
+
+// FailReminder counts failed sending of reminder. It returns number of failures
+func (d Database) FailReminder(ctx context.Context, id int64) (int16, error) {
+	return database.New(d.poll).FailPollReminder(ctx, id)
+}
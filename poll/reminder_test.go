@@ -0,0 +1,49 @@
+package poll
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseReminderLeads(t *testing.T) {
+	leads, err := ParseReminderLeads(" 2h, 50% ,30m")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []ReminderLead{{Before: 2 * time.Hour}, {Percent: 50}, {Before: 30 * time.Minute}}
+	if len(leads) != len(expected) {
+		t.Fatalf("invalid leads %+v", leads)
+	}
+	for i, lead := range leads {
+		if lead != expected[i] {
+			t.Errorf("lead %d: got %+v, expected %+v", i, lead, expected[i])
+		}
+	}
+
+	for _, in := range []string{"", " , ", "0%", "100%", "-2h", "tomorrow", "1h,2h,3h,4h,5h,6h"} {
+		if _, err = ParseReminderLeads(in); !errors.Is(err, ErrInvalidReminder) {
+			t.Errorf("%q: expected invalid reminder error, got: %v", in, err)
+		}
+	}
+}
+
+func TestReminderLeadRemindAt(t *testing.T) {
+	postedAt := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	closesAt := postedAt.Add(24 * time.Hour)
+
+	at, err := ReminderLead{Before: 2 * time.Hour}.RemindAt(postedAt, closesAt)
+	if err != nil || !at.Equal(closesAt.Add(-2*time.Hour)) {
+		t.Fatalf("invalid reminder time %s: %v", at, err)
+	}
+
+	at, err = ReminderLead{Percent: 50}.RemindAt(postedAt, closesAt)
+	if err != nil || !at.Equal(postedAt.Add(12*time.Hour)) {
+		t.Fatalf("invalid reminder time %s: %v", at, err)
+	}
+
+	if _, err = (ReminderLead{Before: 24 * time.Hour}).RemindAt(postedAt, closesAt); !errors.Is(err, ErrInvalidReminder) {
+		t.Fatalf("expected invalid reminder error, got: %v", err)
+	}
+}
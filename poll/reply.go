@@ -0,0 +1,94 @@
+package poll
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/wittano/yomoid/gen/database"
+)
+
+// RepliesPageSize is number of grouped replies shown on single page
+const RepliesPageSize = 10
+
+// Reply is free-text answer. Replies, which differ only by case, are grouped
+type Reply struct {
+	Text  string
+	Count int64
+}
+
+// ReplyParams is free-text answer of user in custom poll
+type ReplyParams struct {
+	PostID int64
+	UserID string
+	// Text removes user's reply, when it's empty
+	Text string
+	// Exclusive removes user's votes for fixed answers. It's set for single choice polls
+	Exclusive bool
+}
+
+// SetReply saves or removes user's free-text answer
+func (d Database) SetReply(ctx context.Context, params ReplyParams) (err error) {
+	tx, err := d.poll.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, tx.Rollback(ctx))
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	q := database.New(tx)
+	text := strings.TrimSpace(params.Text)
+	if text == "" {
+		return q.DeleteCustomPollReply(ctx, database.DeleteCustomPollReplyParams{PostID: params.PostID, UserID: params.UserID})
+	}
+
+	if params.Exclusive {
+		err = q.DeleteCustomPollUserVotes(ctx, database.DeleteCustomPollUserVotesParams{PostID: params.PostID, UserID: params.UserID})
+		if err != nil {
+			return err
+		}
+	}
+
+	return q.UpsertCustomPollReply(ctx, database.UpsertCustomPollReplyParams{PostID: params.PostID, UserID: params.UserID, Reply: text})
+}
+
+// FindUserReply returns free-text answer of user. It's empty, if user didn't reply
+func (d Database) FindUserReply(ctx context.Context, postID int64, userID string) (string, error) {
+	reply, err := database.New(d.poll).FindCustomPollUserReply(ctx, database.FindCustomPollUserReplyParams{PostID: postID, UserID: userID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+
+	return reply, err
+}
+
+// FindReplies returns page of replies from the most popular and number of all grouped replies
+func (d Database) FindReplies(ctx context.Context, postID int64, page int) ([]Reply, int64, error) {
+	q := database.New(d.poll)
+	total, err := q.CountCustomPollReplies(ctx, postID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	data, err := q.FindCustomPollReplies(ctx, database.FindCustomPollRepliesParams{
+		PostID: postID,
+		Offset: int32(page * RepliesPageSize),
+		Limit:  RepliesPageSize,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	replies := make([]Reply, len(data))
+	for i, r := range data {
+		replies[i] = Reply{Text: r.Reply, Count: r.Replies}
+	}
+
+	return replies, total, nil
+}
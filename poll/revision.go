@@ -0,0 +1,357 @@
+package poll
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/wittano/yomoid/gen/database"
+)
+
+var ErrRevisionNotFound = errors.New("database: poll revision not found")
+
+// Revision is immutable version of poll's question, settings and answers
+type Revision struct {
+	ID        int64
+	PollID    int64
+	Revision  int32
+	Question  string
+	IsMulti   bool
+	Duration  int16
+	Answers   []AnswerParams
+	AuthorID  string
+	CreatedAt time.Time
+}
+
+// PostParams describes poll message posted from template
+type PostParams struct {
+	PollID int64
+	// RevisionID is revision of template, from which poll was rendered
+	RevisionID int64
+	GuildID    string
+	ChannelID  string
+	MessageID  string
+	ActorID    string
+	// BatchID groups copies of poll posted by single command. It's empty for single post
+	BatchID string
+	// ThreadID is discussion thread started from poll message
+	ThreadID string
+	ClosesAt time.Time
+	Quorum   Quorum
+	// Engine is EngineNative, when it's empty
+	Engine Engine
+	// Weights are recorded in audit trail. They're saved with custom poll
+	Weights []RoleWeight
+	// Reminders are saved with post. Their PostID is set by RecordPost
+	Reminders []Reminder
+}
+
+// Post is poll message posted from template
+type Post struct {
+	ID         int64
+	PollID     int64
+	RevisionID int64
+	GuildID    string
+	ChannelID  string
+	MessageID  string
+	BatchID    string
+	ThreadID   string
+	PostedBy   string
+	PostedAt   time.Time
+	ClosesAt   time.Time
+	Quorum     Quorum
+	// QuorumMet is nil until poll with quorum closes
+	QuorumMet *bool
+	Engine    Engine
+}
+
+func newPost(p database.PollPost) Post {
+	post := Post{
+		ID:         p.ID,
+		PollID:     p.PollID,
+		RevisionID: p.RevisionID,
+		GuildID:    p.GuildID,
+		ChannelID:  p.ChannelID,
+		MessageID:  p.MessageID,
+		BatchID:    p.BatchID.String,
+		ThreadID:   p.ThreadID.String,
+		PostedBy:   p.PostedBy,
+		PostedAt:   p.PostedAt.Time,
+		ClosesAt:   p.ClosesAt.Time,
+		Quorum: Quorum{
+			Required: p.QuorumRequired.Int32,
+			Percent:  p.QuorumPercent.Int16,
+			RoleID:   p.QuorumRoleID.String,
+			Repost:   p.QuorumRepost,
+		},
+		Engine: Engine(p.Engine),
+	}
+	if p.QuorumMet.Valid {
+		post.QuorumMet = &p.QuorumMet.Bool
+	}
+
+	return post
+}
+
+func (p PostParams) engine() Engine {
+	if p.Engine == "" {
+		return EngineNative
+	}
+
+	return p.Engine
+}
+
+func (d Database) FindRevisions(ctx context.Context, guildID string, pollID int64) ([]Revision, error) {
+	data, err := database.New(d.poll).FindPollRevisions(ctx, database.FindPollRevisionsParams{PollID: pollID, GuildID: guildID})
+	if err != nil {
+		return nil, err
+	} else if len(data) == 0 {
+		return nil, ErrPollNotFound
+	}
+
+	revisions := make([]Revision, len(data))
+	for i, r := range data {
+		revisions[i] = createRevision(r)
+	}
+
+	return revisions, nil
+}
+
+// RollbackPoll restores question, settings and answers from older revision. Rollback is saved as a new revision
+func (d Database) RollbackPoll(ctx context.Context, guildID string, pollID int64, revision int32, actorID string) (rev Revision, err error) {
+	tx, err := d.poll.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return rev, err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, tx.Rollback(ctx))
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	q := database.New(tx)
+	old, err := q.FindPollRevision(ctx, database.FindPollRevisionParams{PollID: pollID, Revision: revision, GuildID: guildID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return rev, ErrRevisionNotFound
+	} else if err != nil {
+		return rev, err
+	}
+
+	rev = createRevision(old)
+	err = q.UpdatePoll(ctx, database.UpdatePollParams{ID: pollID, Question: rev.Question, Duration: rev.Duration, IsMulti: rev.IsMulti})
+	if isUniqueViolation(err) {
+		return rev, errors.Join(ErrPollExists, err)
+	} else if err != nil {
+		return rev, err
+	}
+
+	if err = q.DeletePollOptions(ctx, pollID); err != nil {
+		return rev, err
+	}
+
+	if err = createPollOptions(ctx, q, pollID, rev.Answers); err != nil {
+		return rev, err
+	}
+
+	params := CreatePollParams{
+		Question: rev.Question,
+		GuildID:  guildID,
+		ActorID:  actorID,
+		Duration: rev.Duration,
+		IsMulti:  rev.IsMulti,
+		Answers:  rev.Answers,
+	}
+	if rev.ID, rev.Revision, err = saveRevision(ctx, q, pollID, params); err != nil {
+		return rev, err
+	}
+	rev.AuthorID = actorID
+	rev.CreatedAt = time.Now()
+
+	return rev, audit(ctx, q, AuditEntry{PollID: pollID, GuildID: guildID, ActorID: actorID, Action: AuditEdited})
+}
+
+// RecordPost links posted message with the latest revision of poll
+func (d Database) RecordPost(ctx context.Context, params PostParams) (postID int64, err error) {
+	tx, err := d.poll.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, tx.Rollback(ctx))
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	q := database.New(tx)
+	required, percent, roleID := quorumParams(params.Quorum)
+	postID, err = q.CreatePollPost(ctx, database.CreatePollPostParams{
+		PollID:         params.PollID,
+		RevisionID:     params.RevisionID,
+		GuildID:        params.GuildID,
+		ChannelID:      params.ChannelID,
+		MessageID:      params.MessageID,
+		PostedBy:       params.ActorID,
+		BatchID:        ParseString(params.BatchID),
+		ThreadID:       ParseString(params.ThreadID),
+		ClosesAt:       pgtype.Timestamptz{Time: params.ClosesAt, Valid: !params.ClosesAt.IsZero()},
+		QuorumRequired: required,
+		QuorumPercent:  percent,
+		QuorumRoleID:   roleID,
+		QuorumRepost:   params.Quorum.Repost,
+		Engine:         string(params.engine()),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err = createReminders(ctx, q, postID, params.Reminders); err != nil {
+		return 0, err
+	}
+
+	return postID, audit(ctx, q, AuditEntry{
+		PollID:  params.PollID,
+		GuildID: params.GuildID,
+		ActorID: params.ActorID,
+		Action:  AuditPosted,
+		Details: FormatRoleWeights(params.Weights),
+	})
+}
+
+// FindLatestPosts returns the latest post of poll with all copies posted in the same batch
+func (d Database) FindLatestPosts(ctx context.Context, guildID string, pollID int64) ([]Post, error) {
+	data, err := database.New(d.poll).FindLatestPollPosts(ctx, database.FindLatestPollPostsParams{PollID: pollID, GuildID: guildID})
+	if err != nil {
+		return nil, err
+	}
+
+	posts := make([]Post, len(data))
+	for i, p := range data {
+		posts[i] = newPost(p)
+	}
+
+	return posts, nil
+}
+
+// FindThreadsToArchive returns posts with discussion threads, which polls closed before now
+func (d Database) FindThreadsToArchive(ctx context.Context, now time.Time) ([]Post, error) {
+	data, err := database.New(d.poll).FindPollPostThreadsToArchive(ctx, pgtype.Timestamptz{Time: now, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	posts := make([]Post, len(data))
+	for i, p := range data {
+		posts[i] = newPost(p)
+	}
+
+	return posts, nil
+}
+
+func (d Database) MarkThreadArchived(ctx context.Context, postID int64) error {
+	return database.New(d.poll).MarkPollPostThreadArchived(ctx, postID)
+}
+
+// FailThreadArchive counts failed archiving of discussion thread of post. It returns number of failures
+func (d Database) FailThreadArchive(ctx context.Context, postID int64) (int16, error) {
+	return database.New(d.poll).FailPollPostThreadArchive(ctx, postID)
+}
+
+func (d Database) CountPosts(ctx context.Context, pollID int64) (int64, error) {
+	return database.New(d.poll).CountPollPosts(ctx, pollID)
+}
+
+func saveRevision(ctx context.Context, q *database.Queries, pollID int64, params CreatePollParams) (int64, int32, error) {
+	answers := make([]string, len(params.Answers))
+	emojis := make([]string, len(params.Answers))
+	for i, a := range params.Answers {
+		answers[i] = a.Text
+		emojis[i] = a.Emoji.String()
+	}
+
+	// Next revision number is computed from existing revisions, so concurrent edits of poll are serialized
+	if err := q.LockPollRevisions(ctx, pollID); err != nil {
+		return 0, 0, err
+	}
+
+	row, err := q.CreatePollRevision(ctx, database.CreatePollRevisionParams{
+		PollID:   pollID,
+		Question: params.Question,
+		IsMulti:  params.IsMulti,
+		Duration: params.Duration,
+		Answers:  answers,
+		Emojis:   emojis,
+		AuthorID: params.actor(),
+	})
+
+	return row.ID, row.Revision, err
+}
+
+func createRevision(r database.PollRevision) Revision {
+	answers := make([]AnswerParams, len(r.Answers))
+	for i, a := range r.Answers {
+		answers[i].Text = a
+		if i < len(r.Emojis) {
+			answers[i].Emoji = ParseEmoji(r.Emojis[i])
+		}
+	}
+
+	return Revision{
+		ID:        r.ID,
+		PollID:    r.PollID,
+		Revision:  r.Revision,
+		Question:  r.Question,
+		IsMulti:   r.IsMulti,
+		Duration:  r.Duration,
+		Answers:   answers,
+		AuthorID:  r.AuthorID,
+		CreatedAt: r.CreatedAt.Time,
+	}
+}
+
+// Diff returns changes between two revisions in unified diff-like format. Zero old revision means poll creation
+func Diff(old, new Revision) (lines []string) {
+	created := old.Question == ""
+	change := func(name, oldValue, newValue string) {
+		if !created && oldValue == newValue {
+			return
+		} else if !created {
+			lines = append(lines, "- "+name+": "+oldValue)
+		}
+		lines = append(lines, "+ "+name+": "+newValue)
+	}
+
+	change("question", old.Question, new.Question)
+	change("duration", fmt.Sprintf("%dh", old.Duration), fmt.Sprintf("%dh", new.Duration))
+	change("multiselect", fmt.Sprintf("%t", old.IsMulti), fmt.Sprintf("%t", new.IsMulti))
+
+	oldAnswers := make(map[AnswerParams]bool, len(old.Answers))
+	for _, a := range old.Answers {
+		oldAnswers[a] = true
+	}
+
+	newAnswers := make(map[AnswerParams]bool, len(new.Answers))
+	for _, a := range new.Answers {
+		newAnswers[a] = true
+	}
+
+	for _, a := range old.Answers {
+		if !newAnswers[a] {
+			lines = append(lines, "- answer: "+a.String())
+		}
+	}
+
+	for _, a := range new.Answers {
+		if !oldAnswers[a] {
+			lines = append(lines, "+ answer: "+a.String())
+		}
+	}
+
+	return
+}
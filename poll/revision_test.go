@@ -0,0 +1,47 @@
+package poll
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	old := Revision{
+		Question: "Pizza?",
+		Duration: 24,
+		Answers:  []AnswerParams{{Text: "yes", Emoji: Emoji{Name: "🍕"}}, {Text: "no"}},
+	}
+	new := Revision{
+		Question: "Pizza today?",
+		Duration: 24,
+		IsMulti:  true,
+		Answers:  []AnswerParams{{Text: "yes", Emoji: Emoji{Name: "🍕"}}, {Text: "maybe"}},
+	}
+
+	expected := []string{
+		"- question: Pizza?",
+		"+ question: Pizza today?",
+		"- multiselect: false",
+		"+ multiselect: true",
+		"- answer: no",
+		"+ answer: maybe",
+	}
+	if diff := Diff(old, new); !slices.Equal(diff, expected) {
+		t.Fatalf("invalid diff:\n%v\nexpected:\n%v", diff, expected)
+	}
+
+	if diff := Diff(old, old); len(diff) != 0 {
+		t.Fatalf("diff of the same revisions isn't empty: %v", diff)
+	}
+
+	expected = []string{
+		"+ question: Pizza?",
+		"+ duration: 24h",
+		"+ multiselect: false",
+		"+ answer: 🍕 yes",
+		"+ answer: no",
+	}
+	if diff := Diff(Revision{}, old); !slices.Equal(diff, expected) {
+		t.Fatalf("invalid diff of created poll:\n%v\nexpected:\n%v", diff, expected)
+	}
+}
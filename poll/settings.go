@@ -0,0 +1,54 @@
+package poll
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/wittano/yomoid/gen/database"
+	"github.com/wittano/yomoid/settings"
+)
+
+func (d Database) FindGuildSettings(ctx context.Context, guildID string) (settings.Settings, error) {
+	s, err := database.New(d.poll).FindGuildSettings(ctx, guildID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return settings.Settings{}, settings.ErrNotFound
+	} else if err != nil {
+		return settings.Settings{}, err
+	}
+
+	return settings.Settings{
+		GuildID:         s.GuildID,
+		LinkFixing:      s.LinkFixing,
+		CaptureMode:     settings.CaptureMode(s.CaptureMode),
+		CaptureChannels: s.CaptureChannels,
+		CaptureRoles:    s.CaptureRoles,
+		CaptureQuiet:    s.CaptureQuiet,
+		AdminRoleID:     s.AdminRoleID.String,
+		DefaultDuration: s.DefaultDuration,
+		PollThreads:     s.PollThreads,
+	}, nil
+}
+
+func (d Database) SaveGuildSettings(ctx context.Context, s settings.Settings) error {
+	return database.New(d.poll).SaveGuildSettings(ctx, database.SaveGuildSettingsParams{
+		GuildID:         s.GuildID,
+		LinkFixing:      s.LinkFixing,
+		CaptureMode:     string(s.CaptureMode),
+		CaptureChannels: nonNil(s.CaptureChannels),
+		CaptureRoles:    nonNil(s.CaptureRoles),
+		CaptureQuiet:    s.CaptureQuiet,
+		AdminRoleID:     ParseString(s.AdminRoleID),
+		DefaultDuration: s.DefaultDuration,
+		PollThreads:     s.PollThreads,
+	})
+}
+
+// nonNil prevents saving NULL in not null array columns
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+
+	return s
+}
@@ -0,0 +1,93 @@
+package poll
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/wittano/yomoid/gen/database"
+)
+
+const maxTagLength = 32
+
+// Tag weight is chance of picking poll from tag pool by /poll random
+const (
+	MinTagWeight = 1
+	MaxTagWeight = 100
+)
+
+var (
+	ErrInvalidTag  = errors.New("invalid tag")
+	ErrTagNotFound = errors.New("database: poll doesn't have tag")
+)
+
+// NormalizeTag converts tag to lower case and replaces whitespaces with dash. Tag can contain only letters, digits,
+// dashes and underscores
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.Join(strings.Fields(strings.ToLower(tag)), "-")
+	if tag == "" {
+		return "", fmt.Errorf("%w: tag cannot be empty", ErrInvalidTag)
+	} else if len([]rune(tag)) > maxTagLength {
+		return "", fmt.Errorf("%w: tag cannot be longer than %d characters", ErrInvalidTag, maxTagLength)
+	}
+
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			return "", fmt.Errorf("%w: tag can contain only letters, digits, '-' and '_'", ErrInvalidTag)
+		}
+	}
+
+	return tag, nil
+}
+
+// AddTag tags poll. Weight of already tagged poll is updated
+func (d Database) AddTag(ctx context.Context, guildID string, pollID int64, tag string, weight int16) (err error) {
+	tx, err := d.poll.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, tx.Rollback(ctx))
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	return addTag(ctx, database.New(tx), guildID, pollID, tag, weight)
+}
+
+func addTag(ctx context.Context, q *database.Queries, guildID string, pollID int64, tag string, weight int16) error {
+	tagID, err := q.UpsertPollTag(ctx, database.UpsertPollTagParams{GuildID: guildID, Name: tag})
+	if err != nil {
+		return err
+	}
+
+	n, err := q.AddPollTag(ctx, database.AddPollTagParams{ID: pollID, GuildID: guildID, TagID: tagID, Weight: weight})
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return ErrPollNotFound
+	}
+
+	return nil
+}
+
+func (d Database) RemoveTag(ctx context.Context, guildID string, pollID int64, tag string) error {
+	n, err := database.New(d.poll).RemovePollTag(ctx, database.RemovePollTagParams{ID: pollID, GuildID: guildID, Name: tag})
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return ErrTagNotFound
+	}
+
+	return nil
+}
+
+// FindTags returns up to 25 tags used in guild, which start with prefix
+func (d Database) FindTags(ctx context.Context, guildID, prefix string) ([]string, error) {
+	return database.New(d.poll).FindGuildPollTags(ctx, database.FindGuildPollTagsParams{GuildID: guildID, Prefix: prefix})
+}
@@ -0,0 +1,29 @@
+package poll
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	for tag, expected := range map[string]string{
+		"lunch":         "lunch",
+		"  Movie Night": "movie-night",
+		"game_2":        "game_2",
+		"Zażółć":        "zażółć",
+	} {
+		got, err := NormalizeTag(tag)
+		if err != nil {
+			t.Errorf("%q: %v", tag, err)
+		} else if got != expected {
+			t.Errorf("%q: got %q, expected %q", tag, got, expected)
+		}
+	}
+
+	for _, tag := range []string{"", "   ", "a,b", "#lunch", strings.Repeat("a", maxTagLength+1)} {
+		if _, err := NormalizeTag(tag); !errors.Is(err, ErrInvalidTag) {
+			t.Errorf("%q: expected invalid tag error, got: %v", tag, err)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package poll
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/wittano/yomoid/gen/database"
+)
+
+// VotingMethod decides, how votes of poll are counted
+type VotingMethod string
+
+const (
+	// Plurality is single or multiple choice poll. Answer with the most votes wins
+	Plurality VotingMethod = "plurality"
+	// Approval lets voters approve any number of answers. Answer with the most approvals wins
+	Approval VotingMethod = "approval"
+	// Ranked is ranked-choice poll counted by instant runoff
+	Ranked VotingMethod = "ranked"
+)
+
+func (m VotingMethod) Valid() bool {
+	return m == Plurality || m == Approval || m == Ranked
+}
+
+// NeedsButtons is true for voting methods, which native polls can't express
+func (m VotingMethod) NeedsButtons() bool {
+	return m == Approval || m == Ranked
+}
+
+func (m VotingMethod) String() string {
+	switch m {
+	case Approval:
+		return "approval voting"
+	case Ranked:
+		return "ranked choice"
+	default:
+		return "plurality"
+	}
+}
+
+func newVotingMethod(s string) VotingMethod {
+	if s == "" {
+		return Plurality
+	}
+
+	return VotingMethod(s)
+}
+
+// SetVotingMethod changes voting method of template and records it in audit trail
+func (d Database) SetVotingMethod(ctx context.Context, guildID string, id int64, method VotingMethod, actorID string) (err error) {
+	tx, err := d.poll.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, tx.Rollback(ctx))
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	q := database.New(tx)
+	n, err := q.UpdatePollVotingMethod(ctx, database.UpdatePollVotingMethodParams{ID: id, GuildID: guildID, VotingMethod: string(method)})
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return ErrPollNotFound
+	}
+
+	return audit(ctx, q, AuditEntry{PollID: id, GuildID: guildID, ActorID: actorID, Action: AuditEdited})
+}
+
+// Ballot is ranked vote of single voter
+type Ballot struct {
+	// Ranking are answer indexes from the most preferred
+	Ranking []int
+	// Weight multiplies ballot. Zero means 1
+	Weight int
+}
+
+func (b Ballot) weight() int {
+	return max(b.Weight, 1)
+}
+
+// FindBallots returns ranked ballots of posts
+func (d Database) FindBallots(ctx context.Context, postIDs []int64) ([]Ballot, error) {
+	data, err := database.New(d.poll).FindCustomPollBallots(ctx, postIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ballots            []Ballot
+		lastPost, lastUser = int64(-1), ""
+	)
+	for _, v := range data {
+		if v.PostID != lastPost || v.UserID != lastUser {
+			ballots = append(ballots, Ballot{Weight: int(v.Weight)})
+			lastPost, lastUser = v.PostID, v.UserID
+		}
+
+		b := &ballots[len(ballots)-1]
+		b.Ranking = append(b.Ranking, int(v.Answer))
+	}
+
+	return ballots, nil
+}
+
+// Round is single round of instant runoff
+type Round struct {
+	// Votes are counted for the most preferred answer, which wasn't eliminated yet. Eliminated answers have -1
+	Votes []int
+	// Eliminated are answers removed after round
+	Eliminated []int
+	// Exhausted is number of ballots without answers left in the race. Weights aren't counted
+	Exhausted int
+}
+
+// RunoffResult are rounds of instant runoff. Winners have more than one answer, when the last answers were tied
+type RunoffResult struct {
+	Rounds  []Round
+	Winners []int
+}
+
+// InstantRunoff counts ranked ballots. In each round every ballot counts, with its weight, for its most preferred
+// answer still in the race. Answer with majority of counted votes wins. Otherwise answers with the fewest votes are
+// eliminated. When all remaining answers are tied, they win together
+func InstantRunoff(answers int, ballots []Ballot) (r RunoffResult) {
+	eliminated := make([]bool, answers)
+	remaining := answers
+
+	for remaining > 0 {
+		round := Round{Votes: make([]int, answers)}
+		for i := range round.Votes {
+			if eliminated[i] {
+				round.Votes[i] = -1
+			}
+		}
+
+		counted := 0
+		for _, ballot := range ballots {
+			idx := slices.IndexFunc(ballot.Ranking, func(a int) bool { return a >= 0 && a < answers && !eliminated[a] })
+			if idx < 0 {
+				round.Exhausted++
+				continue
+			}
+
+			round.Votes[ballot.Ranking[idx]] += ballot.weight()
+			counted += ballot.weight()
+		}
+
+		lowest, highest := -1, -1
+		for i, v := range round.Votes {
+			if eliminated[i] {
+				continue
+			}
+			if lowest < 0 || v < lowest {
+				lowest = v
+			}
+			if v > highest {
+				highest = v
+			}
+		}
+
+		if counted > 0 && highest*2 > counted {
+			r.Winners = leaders(round.Votes, highest)
+			r.Rounds = append(r.Rounds, round)
+			return
+		} else if lowest == highest {
+			if counted > 0 {
+				r.Winners = leaders(round.Votes, highest)
+			}
+			r.Rounds = append(r.Rounds, round)
+			return
+		}
+
+		for i, v := range round.Votes {
+			if !eliminated[i] && v == lowest {
+				eliminated[i] = true
+				round.Eliminated = append(round.Eliminated, i)
+				remaining--
+			}
+		}
+		r.Rounds = append(r.Rounds, round)
+	}
+
+	return
+}
+
+func leaders(votes []int, highest int) (winners []int) {
+	for i, v := range votes {
+		if v == highest {
+			winners = append(winners, i)
+		}
+	}
+
+	return
+}
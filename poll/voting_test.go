@@ -0,0 +1,78 @@
+package poll
+
+import (
+	"slices"
+	"testing"
+)
+
+func rankings(r ...[]int) []Ballot {
+	ballots := make([]Ballot, len(r))
+	for i, ranking := range r {
+		ballots[i] = Ballot{Ranking: ranking}
+	}
+
+	return ballots
+}
+
+func TestInstantRunoff(t *testing.T) {
+	// A has the most first choices, but C's voters prefer B
+	ballots := rankings(
+		[]int{0}, []int{0}, []int{0}, []int{0},
+		[]int{1, 0}, []int{1, 2}, []int{1},
+		[]int{2, 1}, []int{2, 1},
+	)
+
+	r := InstantRunoff(3, ballots)
+	if len(r.Rounds) != 2 {
+		t.Fatalf("rounds = %d, want 2", len(r.Rounds))
+	}
+
+	if !slices.Equal(r.Rounds[0].Votes, []int{4, 3, 2}) || !slices.Equal(r.Rounds[0].Eliminated, []int{2}) {
+		t.Fatalf("invalid first round: %+v", r.Rounds[0])
+	}
+
+	if !slices.Equal(r.Rounds[1].Votes, []int{4, 5, -1}) || !slices.Equal(r.Winners, []int{1}) {
+		t.Fatalf("invalid result: %+v", r)
+	}
+}
+
+func TestInstantRunoffMajority(t *testing.T) {
+	r := InstantRunoff(2, rankings([]int{0}, []int{0}, []int{1}))
+	if len(r.Rounds) != 1 || !slices.Equal(r.Winners, []int{0}) {
+		t.Fatalf("invalid result: %+v", r)
+	}
+}
+
+func TestInstantRunoffTie(t *testing.T) {
+	r := InstantRunoff(3, rankings([]int{0}, []int{1}))
+	if !slices.Equal(r.Winners, []int{0, 1}) {
+		t.Fatalf("winners = %v, want tie of 0 and 1", r.Winners)
+	}
+
+	if r.Rounds[0].Eliminated[0] != 2 {
+		t.Fatalf("answer without votes wasn't eliminated: %+v", r.Rounds[0])
+	}
+}
+
+func TestInstantRunoffExhausted(t *testing.T) {
+	r := InstantRunoff(3, rankings([]int{0}, []int{0}, []int{1}, []int{1}, []int{2}))
+	if r.Rounds[1].Exhausted != 1 {
+		t.Fatalf("exhausted = %d, want 1", r.Rounds[1].Exhausted)
+	}
+}
+
+func TestInstantRunoffWithoutBallots(t *testing.T) {
+	if r := InstantRunoff(2, nil); len(r.Winners) != 0 {
+		t.Fatalf("winners = %v, want none", r.Winners)
+	}
+}
+
+func TestInstantRunoffWeighted(t *testing.T) {
+	// Single maintainer with weight 3 outvotes two members
+	ballots := []Ballot{{Ranking: []int{0}, Weight: 3}, {Ranking: []int{1}}, {Ranking: []int{1}}}
+
+	r := InstantRunoff(2, ballots)
+	if !slices.Equal(r.Rounds[0].Votes, []int{3, 2}) || !slices.Equal(r.Winners, []int{0}) {
+		t.Fatalf("invalid result: %+v", r)
+	}
+}
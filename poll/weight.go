@@ -0,0 +1,88 @@
+package poll
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidWeight = errors.New("poll: invalid role weight")
+
+const (
+	// MaxRoleWeights limits weighted roles of single poll
+	MaxRoleWeights = 10
+	// MaxWeight is the highest weight of vote. Votes of members without weighted roles have weight 1
+	MaxWeight = 10
+)
+
+// RoleWeight multiplies votes of role members
+type RoleWeight struct {
+	RoleID string
+	Weight int16
+}
+
+func (w RoleWeight) String() string {
+	return fmt.Sprintf("<@&%s>=%d", w.RoleID, w.Weight)
+}
+
+var roleWeightRegex = regexp.MustCompile(`^<@&(\d+)>\s*[=:x×]?\s*(\d+)$`)
+
+// ParseRoleWeights parses comma separated role mentions with weights, e.g. "@Maintainers=2, @Core=3"
+func ParseRoleWeights(s string) ([]RoleWeight, error) {
+	var weights []RoleWeight
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		match := roleWeightRegex.FindStringSubmatch(part)
+		if match == nil {
+			return nil, fmt.Errorf("%w: %q must be role mention with weight, e.g. @Maintainers=2", ErrInvalidWeight, part)
+		}
+
+		weight, err := strconv.Atoi(match[2])
+		if err != nil || weight < 2 || weight > MaxWeight {
+			return nil, fmt.Errorf("%w: weight of <@&%s> must be between 2 and %d", ErrInvalidWeight, match[1], MaxWeight)
+		}
+
+		if slices.ContainsFunc(weights, func(w RoleWeight) bool { return w.RoleID == match[1] }) {
+			return nil, fmt.Errorf("%w: role <@&%s> has many weights", ErrInvalidWeight, match[1])
+		}
+
+		weights = append(weights, RoleWeight{RoleID: match[1], Weight: int16(weight)})
+	}
+
+	if len(weights) == 0 {
+		return nil, fmt.Errorf("%w: pass role mentions with weights, e.g. @Maintainers=2", ErrInvalidWeight)
+	} else if len(weights) > MaxRoleWeights {
+		return nil, fmt.Errorf("%w: poll can have up to %d weighted roles", ErrInvalidWeight, MaxRoleWeights)
+	}
+
+	return weights, nil
+}
+
+// FormatRoleWeights is inverse of ParseRoleWeights
+func FormatRoleWeights(weights []RoleWeight) string {
+	parts := make([]string, len(weights))
+	for i, w := range weights {
+		parts[i] = w.String()
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// VoterWeight returns the highest weight of member's roles
+func VoterWeight(weights []RoleWeight, roles []string) int16 {
+	var weight int16 = 1
+	for _, w := range weights {
+		if w.Weight > weight && slices.Contains(roles, w.RoleID) {
+			weight = w.Weight
+		}
+	}
+
+	return weight
+}
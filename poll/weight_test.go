@@ -0,0 +1,43 @@
+package poll
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParseRoleWeights(t *testing.T) {
+	weights, err := ParseRoleWeights("<@&1>=2, <@&2> 3, <@&3>×10")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []RoleWeight{{RoleID: "1", Weight: 2}, {RoleID: "2", Weight: 3}, {RoleID: "3", Weight: 10}}
+	if !slices.Equal(weights, want) {
+		t.Fatalf("weights = %v, want %v", weights, want)
+	}
+
+	if s := FormatRoleWeights(weights); s != "<@&1>=2, <@&2>=3, <@&3>=10" {
+		t.Fatalf("invalid formatted weights %q", s)
+	}
+}
+
+func TestParseRoleWeightsInvalid(t *testing.T) {
+	for _, s := range []string{"", "@Maintainers=2", "<@&1>=1", "<@&1>=11", "<@&1>=2, <@&1>=3", "<@&1>"} {
+		if _, err := ParseRoleWeights(s); !errors.Is(err, ErrInvalidWeight) {
+			t.Fatalf("%q: error = %v, want ErrInvalidWeight", s, err)
+		}
+	}
+}
+
+func TestVoterWeight(t *testing.T) {
+	weights := []RoleWeight{{RoleID: "1", Weight: 2}, {RoleID: "2", Weight: 3}}
+
+	if w := VoterWeight(weights, []string{"1", "2"}); w != 3 {
+		t.Fatalf("weight = %d, want the highest weight 3", w)
+	}
+
+	if w := VoterWeight(weights, []string{"5"}); w != 1 {
+		t.Fatalf("weight = %d, want 1", w)
+	}
+}
@@ -0,0 +1,157 @@
+package settings
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+type CaptureMode string
+
+const (
+	CaptureOff        CaptureMode = "off"
+	CaptureAllowList  CaptureMode = "allowlist"
+	CaptureEverywhere CaptureMode = "everywhere"
+)
+
+func (m CaptureMode) Valid() bool {
+	return m == CaptureOff || m == CaptureAllowList || m == CaptureEverywhere
+}
+
+type Settings struct {
+	GuildID         string
+	LinkFixing      bool
+	CaptureMode     CaptureMode
+	CaptureChannels []string
+	CaptureRoles    []string
+	// CaptureQuiet reacts with emoji on captured poll instead of replying
+	CaptureQuiet    bool
+	AdminRoleID     string
+	DefaultDuration int16
+	// PollThreads starts discussion thread from every poll posted by /poll create
+	PollThreads bool
+}
+
+func Default(guildID string) Settings {
+	return Settings{
+		GuildID:         guildID,
+		LinkFixing:      true,
+		CaptureMode:     CaptureAllowList,
+		DefaultDuration: 24,
+	}
+}
+
+// Captures reports if native poll posted on channel by member with roles should be saved.
+// Empty role list means, that polls from all members are captured
+func (s Settings) Captures(channelID string, roles []string) bool {
+	switch s.CaptureMode {
+	case CaptureEverywhere:
+	case CaptureAllowList:
+		if !slices.Contains(s.CaptureChannels, channelID) {
+			return false
+		}
+	default:
+		return false
+	}
+
+	if len(s.CaptureRoles) == 0 {
+		return true
+	}
+
+	return slices.ContainsFunc(roles, func(role string) bool {
+		return slices.Contains(s.CaptureRoles, role)
+	})
+}
+
+var ErrNotFound = errors.New("settings: guild settings not found")
+
+type Store interface {
+	// FindGuildSettings returns ErrNotFound, if guild doesn't have saved settings
+	FindGuildSettings(ctx context.Context, guildID string) (Settings, error)
+	SaveGuildSettings(ctx context.Context, s Settings) error
+}
+
+type cacheEntry struct {
+	settings Settings
+	expireAt time.Time
+}
+
+// Service caches guild settings for ttl. Cached entry is invalidated after every update
+type Service struct {
+	store Store
+	ttl   time.Duration
+	now   func() time.Time
+
+	m     sync.RWMutex
+	cache map[string]cacheEntry
+}
+
+func NewService(store Store, ttl time.Duration) *Service {
+	return &Service{
+		store: store,
+		ttl:   ttl,
+		now:   time.Now,
+		cache: make(map[string]cacheEntry),
+	}
+}
+
+func (s *Service) Get(ctx context.Context, guildID string) (Settings, error) {
+	s.m.RLock()
+	entry, ok := s.cache[guildID]
+	s.m.RUnlock()
+
+	if ok && s.now().Before(entry.expireAt) {
+		return clone(entry.settings), nil
+	}
+
+	settings, err := s.store.FindGuildSettings(ctx, guildID)
+	if errors.Is(err, ErrNotFound) {
+		settings = Default(guildID)
+	} else if err != nil {
+		return Default(guildID), err
+	}
+
+	s.m.Lock()
+	s.cache[guildID] = cacheEntry{settings: settings, expireAt: s.now().Add(s.ttl)}
+	s.m.Unlock()
+
+	return clone(settings), nil
+}
+
+// Update applies fn on current guild settings and saves result
+func (s *Service) Update(ctx context.Context, guildID string, fn func(s *Settings) error) (Settings, error) {
+	settings, err := s.store.FindGuildSettings(ctx, guildID)
+	if errors.Is(err, ErrNotFound) {
+		settings = Default(guildID)
+	} else if err != nil {
+		return Settings{}, err
+	}
+
+	if err = fn(&settings); err != nil {
+		return Settings{}, err
+	}
+	settings.GuildID = guildID
+
+	if err = s.store.SaveGuildSettings(ctx, settings); err != nil {
+		return Settings{}, err
+	}
+
+	s.Invalidate(guildID)
+
+	return settings, nil
+}
+
+func (s *Service) Invalidate(guildID string) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	delete(s.cache, guildID)
+}
+
+func clone(s Settings) Settings {
+	s.CaptureChannels = slices.Clone(s.CaptureChannels)
+	s.CaptureRoles = slices.Clone(s.CaptureRoles)
+	return s
+}
@@ -0,0 +1,96 @@
+package settings
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type memoryStore struct {
+	data  map[string]Settings
+	reads int
+}
+
+func (m *memoryStore) FindGuildSettings(_ context.Context, guildID string) (Settings, error) {
+	m.reads++
+
+	s, ok := m.data[guildID]
+	if !ok {
+		return Settings{}, ErrNotFound
+	}
+
+	return s, nil
+}
+
+func (m *memoryStore) SaveGuildSettings(_ context.Context, s Settings) error {
+	m.data[s.GuildID] = s
+	return nil
+}
+
+func TestServiceCache(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		store = &memoryStore{data: map[string]Settings{}}
+		now   = time.Now()
+		s     = NewService(store, time.Minute)
+	)
+	s.now = func() time.Time { return now }
+
+	got, err := s.Get(ctx, "guild")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.LinkFixing || got.DefaultDuration != 24 {
+		t.Fatalf("missing guild settings should be default. got: %+v", got)
+	}
+
+	if _, err = s.Get(ctx, "guild"); err != nil {
+		t.Fatal(err)
+	}
+	if store.reads != 1 {
+		t.Fatalf("settings should be cached. store reads: %d", store.reads)
+	}
+
+	if _, err = s.Update(ctx, "guild", func(s *Settings) error {
+		s.LinkFixing = false
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ = s.Get(ctx, "guild"); got.LinkFixing {
+		t.Fatal("cache wasn't invalidated after update")
+	}
+
+	reads := store.reads
+	now = now.Add(2 * time.Minute)
+	if _, err = s.Get(ctx, "guild"); err != nil {
+		t.Fatal(err)
+	}
+	if store.reads != reads+1 {
+		t.Fatal("expired cache entry should be reloaded")
+	}
+}
+
+func TestCaptures(t *testing.T) {
+	data := map[string]struct {
+		settings Settings
+		exp      bool
+	}{
+		"everywhere":              {Settings{CaptureMode: CaptureEverywhere}, true},
+		"listed channel":          {Settings{CaptureMode: CaptureAllowList, CaptureChannels: []string{"1", "2"}}, true},
+		"unlisted channel":        {Settings{CaptureMode: CaptureAllowList, CaptureChannels: []string{"1"}}, false},
+		"empty allow-list":        {Settings{CaptureMode: CaptureAllowList}, false},
+		"capture disabled":        {Settings{CaptureMode: CaptureOff, CaptureChannels: []string{"2"}}, false},
+		"member with listed role": {Settings{CaptureMode: CaptureEverywhere, CaptureRoles: []string{"admin"}}, true},
+		"member without role":     {Settings{CaptureMode: CaptureEverywhere, CaptureRoles: []string{"mod"}}, false},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			if got := d.settings.Captures("2", []string{"member", "admin"}); got != d.exp {
+				t.Fatalf("invalid result. Expected: %v, got: %v", d.exp, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package tracing
+
+import "testing"
+
+func TestQueryName(t *testing.T) {
+	data := map[string]string{
+		"-- name: FindPollByID :one\nselect p.id from poll p": "FindPollByID",
+		"  begin isolation level read committed":              "BEGIN",
+		"commit":                                              "COMMIT",
+		"":                                                    "",
+	}
+
+	for in, exp := range data {
+		t.Run(in, func(t *testing.T) {
+			if got := queryName(in); got != exp {
+				t.Fatalf("invalid query name. Expected: %q, got: %q", exp, got)
+			}
+		})
+	}
+}